@@ -0,0 +1,26 @@
+/*
+Package api holds the pieces shared by the go-inoreader API packages, such as
+the default base URL that endpoint paths are resolved against.
+*/
+package api
+
+import (
+	"strings"
+
+	"github.com/go-resty/resty/v2"
+)
+
+// DefaultBaseURL is the Inoreader API root every endpoint path is relative to.
+const DefaultBaseURL = "https://www.inoreader.com/reader/api/0"
+
+// Returns the URL to request for the endpoint `path`. A resty.Client with a
+// host URL set resolves relative paths itself; clients without one fall back
+// to DefaultBaseURL.
+func Endpoint(rc *resty.Client, path string) string {
+
+	if rc.HostURL != "" {
+		return path
+	}
+
+	return DefaultBaseURL + "/" + strings.TrimLeft(path, "/")
+}
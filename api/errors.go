@@ -0,0 +1,131 @@
+package api
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/go-resty/resty/v2"
+	"github.com/pkg/errors"
+)
+
+// Longest response body excerpt kept in an APIError.
+const maxBodyExcerpt = 512
+
+// APIError is returned when the Inoreader API answers with a status outside
+// the 2xx range.
+type APIError struct {
+	StatusCode int
+	Method     string
+	Endpoint   string
+	Body       string
+	RequestID  string
+}
+
+func (e *APIError) Error() string {
+
+	msg := fmt.Sprintf("Inoreader API %s %s: %d %s", e.Method, e.Endpoint, e.StatusCode, http.StatusText(e.StatusCode))
+	if e.RequestID != "" {
+		msg = fmt.Sprintf("%s (request %s)", msg, e.RequestID)
+	}
+
+	if e.Body != "" {
+		msg = fmt.Sprintf("%s: %s", msg, e.Body)
+	}
+
+	return msg
+}
+
+// Returns an excerpt of the response body suitable for an error message.
+func bodyExcerpt(body []byte) string {
+
+	s := strings.TrimSpace(string(body))
+	if len(s) > maxBodyExcerpt {
+		s = s[:maxBodyExcerpt] + "..."
+	}
+
+	return s
+}
+
+// CheckResponse turns the result of a resty request into an error: the
+// transport error if there is one, an *APIError if the status is not 2xx,
+// and nil otherwise.
+func CheckResponse(resp *resty.Response, err error) error {
+
+	if err != nil {
+		return err
+	}
+
+	if resp.IsSuccess() {
+		return nil
+	}
+
+	apiErr := &APIError{
+		StatusCode: resp.StatusCode(),
+		Body:       bodyExcerpt(resp.Body()),
+		RequestID:  resp.Header().Get("X-Request-Id"),
+	}
+
+	if req := resp.Request; req != nil {
+		apiErr.Method = req.Method
+		apiErr.Endpoint = req.URL
+		if raw := req.RawRequest; raw != nil {
+			apiErr.Endpoint = raw.URL.Path
+		}
+	}
+
+	return apiErr
+}
+
+// CheckResponseContext is CheckResponse for a request sent with ctx. Once
+// ctx is cancelled or past its deadline, ctx.Err() is returned in place of
+// the transport error it caused.
+func CheckResponseContext(ctx context.Context, resp *resty.Response, err error) error {
+
+	if err != nil && ctx.Err() != nil {
+		return ctx.Err()
+	}
+
+	return CheckResponse(resp, err)
+}
+
+// Returns the status code of the *APIError in err's chain, or 0.
+func statusCode(err error) int {
+
+	var apiErr *APIError
+	if errors.As(err, &apiErr) {
+		return apiErr.StatusCode
+	}
+
+	return 0
+}
+
+// IsUnauthorized reports whether err is an *APIError with status 401, for
+// example because the token was revoked.
+func IsUnauthorized(err error) bool {
+	return statusCode(err) == http.StatusUnauthorized
+}
+
+// IsForbidden reports whether err is an *APIError with status 403.
+func IsForbidden(err error) bool {
+	return statusCode(err) == http.StatusForbidden
+}
+
+// IsNotFound reports whether err is an *APIError with status 404.
+func IsNotFound(err error) bool {
+	return statusCode(err) == http.StatusNotFound
+}
+
+// IsRateLimited reports whether err is an *APIError with status 429, which
+// Inoreader sends once a zone's daily request limit is used up.
+func IsRateLimited(err error) bool {
+	return statusCode(err) == http.StatusTooManyRequests
+}
+
+// IsServerError reports whether err is an *APIError with a 5xx status,
+// such as 503 during maintenance.
+func IsServerError(err error) bool {
+	code := statusCode(err)
+	return code >= 500 && code < 600
+}
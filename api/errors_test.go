@@ -0,0 +1,59 @@
+package api
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/go-resty/resty/v2"
+	"github.com/pkg/errors"
+)
+
+func TestCheckResponse(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/ok":
+			w.Write([]byte("OK"))
+		case "/unauthorized":
+			w.Header().Set("X-Request-Id", "abc123")
+			w.WriteHeader(http.StatusUnauthorized)
+			w.Write([]byte("<html>Unauthorized</html>"))
+		case "/limited":
+			w.WriteHeader(http.StatusTooManyRequests)
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer srv.Close()
+
+	rc := resty.New().SetHostURL(srv.URL)
+
+	if err := CheckResponse(rc.R().Post("ok")); err != nil {
+		t.Fatal(err)
+	}
+
+	err := CheckResponse(rc.R().Post("unauthorized"))
+
+	var apiErr *APIError
+	if !errors.As(err, &apiErr) {
+		t.Fatalf("error = %v, want an *APIError", err)
+	}
+
+	if apiErr.StatusCode != http.StatusUnauthorized || apiErr.Method != "POST" ||
+		apiErr.Endpoint != "/unauthorized" || apiErr.RequestID != "abc123" ||
+		apiErr.Body != "<html>Unauthorized</html>" {
+		t.Fatalf("APIError = %#v", apiErr)
+	}
+
+	if !IsUnauthorized(errors.Wrap(err, "Could not get user info")) {
+		t.Fatal("IsUnauthorized = false for a wrapped 401")
+	}
+
+	if err := CheckResponse(rc.R().Get("limited")); !IsRateLimited(err) || IsNotFound(err) {
+		t.Fatalf("error = %v, want a 429 APIError", err)
+	}
+
+	if err := CheckResponse(rc.R().Get("missing")); !IsNotFound(err) {
+		t.Fatalf("error = %v, want a 404 APIError", err)
+	}
+}
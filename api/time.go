@@ -0,0 +1,101 @@
+package api
+
+import (
+	"encoding/json"
+	"strconv"
+	"time"
+)
+
+// UsecTime is a time the API writes in microseconds since the epoch, as a
+// string such as "1614556800123456". It encodes back the same way.
+//
+// UsecTime, MsecTime and UnixTime decode from either a JSON number or a
+// string. A value of 0, an empty string or null decodes to the zero
+// time.Time, which encodes as 0.
+type UsecTime struct {
+	time.Time
+}
+
+// MsecTime is a time the API writes in milliseconds since the epoch, as a
+// string such as "1614556800123".
+type MsecTime struct {
+	time.Time
+}
+
+// UnixTime is a time the API writes in seconds since the epoch, as a
+// number such as 1614556800.
+type UnixTime struct {
+	time.Time
+}
+
+// Decodes a timestamp counted in units of `unit` since the epoch.
+func decodeTimestamp(data []byte, unit time.Duration) (time.Time, error) {
+
+	var n FlexInt
+	if err := json.Unmarshal(data, &n); err != nil {
+		return time.Time{}, err
+	}
+
+	if n == 0 {
+		return time.Time{}, nil
+	}
+
+	perSecond := int64(time.Second / unit)
+	sec, frac := int64(n)/perSecond, int64(n)%perSecond
+
+	return time.Unix(sec, frac*int64(unit)), nil
+}
+
+// Returns t counted in units of `unit` since the epoch, or 0 for the zero
+// time.
+func encodeTimestamp(t time.Time, unit time.Duration) int64 {
+
+	if t.IsZero() {
+		return 0
+	}
+
+	perSecond := int64(time.Second / unit)
+	return t.Unix()*perSecond + int64(t.Nanosecond())/int64(unit)
+}
+
+func (t *UsecTime) UnmarshalJSON(data []byte) (err error) {
+	t.Time, err = decodeTimestamp(data, time.Microsecond)
+	return err
+}
+
+func (t UsecTime) MarshalJSON() ([]byte, error) {
+	return []byte(strconv.Quote(strconv.FormatInt(t.Usec(), 10))), nil
+}
+
+// Usec returns t in microseconds since the epoch, or 0 for the zero time.
+func (t UsecTime) Usec() int64 {
+	return encodeTimestamp(t.Time, time.Microsecond)
+}
+
+func (t *MsecTime) UnmarshalJSON(data []byte) (err error) {
+	t.Time, err = decodeTimestamp(data, time.Millisecond)
+	return err
+}
+
+func (t MsecTime) MarshalJSON() ([]byte, error) {
+	return []byte(strconv.Quote(strconv.FormatInt(t.Msec(), 10))), nil
+}
+
+// Msec returns t in milliseconds since the epoch, or 0 for the zero time.
+func (t MsecTime) Msec() int64 {
+	return encodeTimestamp(t.Time, time.Millisecond)
+}
+
+func (t *UnixTime) UnmarshalJSON(data []byte) (err error) {
+	t.Time, err = decodeTimestamp(data, time.Second)
+	return err
+}
+
+func (t UnixTime) MarshalJSON() ([]byte, error) {
+	return []byte(strconv.FormatInt(t.Sec(), 10)), nil
+}
+
+// Sec returns t in seconds since the epoch, or 0 for the zero time.
+func (t UnixTime) Sec() int64 {
+	return encodeTimestamp(t.Time, time.Second)
+}
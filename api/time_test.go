@@ -0,0 +1,57 @@
+package api
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+)
+
+func TestTimestamps(t *testing.T) {
+	var v struct {
+		Usec UsecTime `json:"usec"`
+		Msec MsecTime `json:"msec"`
+		Unix UnixTime `json:"unix"`
+	}
+
+	in := `{"usec":"1614556800123456","msec":"1614556800123","unix":1614556800}`
+	if err := json.Unmarshal([]byte(in), &v); err != nil {
+		t.Fatal(err)
+	}
+
+	base := time.Date(2021, 3, 1, 0, 0, 0, 0, time.UTC)
+	if !v.Usec.Equal(base.Add(123456*time.Microsecond)) || !v.Msec.Equal(base.Add(123*time.Millisecond)) || !v.Unix.Equal(base) {
+		t.Fatalf("decoded %s, %s, %s", v.Usec, v.Msec, v.Unix)
+	}
+
+	out, err := json.Marshal(v)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if string(out) != in {
+		t.Fatalf("encoded %s, want %s", out, in)
+	}
+
+	// The other JSON type for each unit, and the unset values.
+	in = `{"usec":1614556800123456,"msec":"","unix":"0"}`
+	if err := json.Unmarshal([]byte(in), &v); err != nil {
+		t.Fatal(err)
+	}
+
+	if v.Usec.Usec() != 1614556800123456 || !v.Msec.IsZero() || !v.Unix.IsZero() {
+		t.Fatalf("decoded %s, %s, %s", v.Usec, v.Msec, v.Unix)
+	}
+
+	out, err = json.Marshal(v)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if want := `{"usec":"1614556800123456","msec":"0","unix":0}`; string(out) != want {
+		t.Fatalf("encoded %s, want %s", out, want)
+	}
+
+	if err := json.Unmarshal([]byte(`{"unix":"yesterday"}`), &v); err == nil {
+		t.Fatal("decoded an invalid timestamp")
+	}
+}
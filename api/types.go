@@ -0,0 +1,57 @@
+package api
+
+import (
+	"bytes"
+	"encoding/json"
+	"strconv"
+
+	"github.com/pkg/errors"
+)
+
+// FlexInt is an integer that the API writes as a JSON number in some
+// responses and as a string in others, such as "updated" or "length". An
+// empty string or null decodes as 0. It always encodes as a number.
+type FlexInt int64
+
+func (n *FlexInt) UnmarshalJSON(data []byte) error {
+
+	data = bytes.TrimSpace(data)
+	if bytes.Equal(data, []byte("null")) {
+		*n = 0
+		return nil
+	}
+
+	if len(data) > 0 && data[0] == '"' {
+		var s string
+		if err := json.Unmarshal(data, &s); err != nil {
+			return err
+		}
+		data = []byte(s)
+		if len(data) == 0 {
+			*n = 0
+			return nil
+		}
+	}
+
+	v, err := strconv.ParseInt(string(data), 10, 64)
+	if err != nil {
+		// Some counters come as floats, such as 3.0.
+		f, ferr := strconv.ParseFloat(string(data), 64)
+		if ferr != nil || f != float64(int64(f)) {
+			return errors.Errorf("Unable to decode %s as an integer", data)
+		}
+		v = int64(f)
+	}
+
+	*n = FlexInt(v)
+	return nil
+}
+
+func (n FlexInt) MarshalJSON() ([]byte, error) {
+	return []byte(strconv.FormatInt(int64(n), 10)), nil
+}
+
+// Int64 returns n as an int64.
+func (n FlexInt) Int64() int64 {
+	return int64(n)
+}
@@ -0,0 +1,47 @@
+package api
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func TestFlexInt(t *testing.T) {
+	cases := map[string]FlexInt{
+		`1614556800`:       1614556800,
+		`"1614556800"`:     1614556800,
+		`"-1"`:             -1,
+		`""`:               0,
+		`null`:             0,
+		`3.0`:              3,
+		`"12345678901234"`: 12345678901234,
+	}
+
+	for in, want := range cases {
+		var n FlexInt
+		if err := json.Unmarshal([]byte(in), &n); err != nil {
+			t.Fatalf("%s: %v", in, err)
+		}
+
+		if n != want {
+			t.Fatalf("%s decoded to %d, want %d", in, n, want)
+		}
+	}
+
+	for _, in := range []string{`"abc"`, `1.5`, `true`, `{}`} {
+		var n FlexInt
+		if err := json.Unmarshal([]byte(in), &n); err == nil {
+			t.Fatalf("%s decoded to %d, want an error", in, n)
+		}
+	}
+
+	out, err := json.Marshal(struct {
+		N FlexInt `json:"n"`
+	}{42})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if string(out) != `{"n":42}` {
+		t.Fatalf("encoded %s", out)
+	}
+}
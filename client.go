@@ -0,0 +1,272 @@
+package inoreader
+
+import (
+	"context"
+	"net/http"
+	"time"
+
+	"github.com/go-resty/resty/v2"
+	"github.com/hyperreal64/go-inoreader/api"
+	"github.com/hyperreal64/go-inoreader/stream"
+	"github.com/hyperreal64/go-inoreader/subscription"
+	"github.com/hyperreal64/go-inoreader/tags"
+	"github.com/hyperreal64/go-inoreader/userinfo"
+)
+
+// DefaultUserAgent is sent with every request unless WithUserAgent is given.
+const DefaultUserAgent = "go-inoreader"
+
+// Client talks to the Inoreader API. It wraps a resty.Client whose host URL
+// is the configured base URL, so every endpoint can be pointed at a proxy or
+// a local stand-in server.
+type Client struct {
+	baseURL    string
+	httpClient *http.Client
+	userAgent  string
+	timeout    time.Duration
+	retry      *RetryPolicy
+	budgets    map[Zone]*RateBudget
+	rc         *resty.Client
+	resolver   *stream.Resolver
+}
+
+// Option configures a Client created by NewClient.
+type Option func(*Client)
+
+// WithBaseURL sets the API root that endpoint paths are resolved against.
+// Defaults to api.DefaultBaseURL.
+func WithBaseURL(baseURL string) Option {
+	return func(c *Client) {
+		c.baseURL = baseURL
+	}
+}
+
+// WithHTTPClient sets the *http.Client requests are sent through, typically
+// the OAuth2 client from config.NewOauth2HTTPClient.
+func WithHTTPClient(hc *http.Client) Option {
+	return func(c *Client) {
+		c.httpClient = hc
+	}
+}
+
+// WithUserAgent sets the User-Agent header sent with every request.
+func WithUserAgent(userAgent string) Option {
+	return func(c *Client) {
+		c.userAgent = userAgent
+	}
+}
+
+// WithTimeout sets the timeout applied to every request. Zero means no
+// timeout.
+func WithTimeout(timeout time.Duration) Option {
+	return func(c *Client) {
+		c.timeout = timeout
+	}
+}
+
+// NewClient returns a Client configured by opts.
+func NewClient(opts ...Option) *Client {
+
+	c := &Client{
+		baseURL:   api.DefaultBaseURL,
+		userAgent: DefaultUserAgent,
+	}
+
+	for _, opt := range opts {
+		opt(c)
+	}
+
+	if c.httpClient == nil {
+		c.httpClient = &http.Client{}
+	}
+
+	if c.retry != nil {
+		// Copy the client so the caller's one is left unchanged.
+		hc := *c.httpClient
+		rt := newRetryTransport(hc.Transport, *c.retry)
+		rt.discarded = c.recordRateHeaders
+		hc.Transport = rt
+		c.httpClient = &hc
+	}
+
+	c.budget(ZoneRead)
+	c.budget(ZoneWrite)
+
+	c.rc = resty.NewWithClient(c.httpClient).
+		SetHostURL(c.baseURL).
+		SetHeader("User-Agent", c.userAgent).
+		SetTimeout(c.timeout).
+		OnBeforeRequest(c.checkRateBudget).
+		OnAfterResponse(c.updateRateBudgets)
+
+	c.resolver = stream.NewResolver(c.rc)
+
+	return c
+}
+
+// BaseURL returns the API root the Client sends requests to.
+func (c *Client) BaseURL() string {
+	return c.baseURL
+}
+
+// Resty returns the underlying resty.Client, for use with the package-level
+// functions in stream, subscription, tags and userinfo.
+func (c *Client) Resty() *resty.Client {
+	return c.rc
+}
+
+// GetStreamContents calls stream.GetStreamContentsContext.
+func (c *Client) GetStreamContents(ctx context.Context, params map[string]string) (*stream.StreamContents, error) {
+	return stream.GetStreamContentsContext(ctx, c.rc, params)
+}
+
+// GetStreamContentsWithOptions calls stream.GetStreamContentsWithOptions.
+func (c *Client) GetStreamContentsWithOptions(ctx context.Context, opts *stream.StreamContentsOptions) (*stream.StreamContents, error) {
+	return stream.GetStreamContentsWithOptions(ctx, c.rc, opts)
+}
+
+// NewItemIterator calls stream.NewItemIterator.
+func (c *Client) NewItemIterator(ctx context.Context, opts *stream.StreamContentsOptions, max int) *stream.ItemIterator {
+	return stream.NewItemIterator(ctx, c.rc, opts, max)
+}
+
+// GetItemIDs calls stream.GetItemIDs.
+func (c *Client) GetItemIDs(ctx context.Context, opts *stream.ItemIDsOptions) (*stream.ItemIDs, error) {
+	return stream.GetItemIDs(ctx, c.rc, opts)
+}
+
+// NewItemRefIterator calls stream.NewItemRefIterator.
+func (c *Client) NewItemRefIterator(ctx context.Context, opts *stream.ItemIDsOptions, max int) *stream.ItemRefIterator {
+	return stream.NewItemRefIterator(ctx, c.rc, opts, max)
+}
+
+// GetItemsByID calls stream.GetItemsByID.
+func (c *Client) GetItemsByID(ctx context.Context, ids []string, opts *stream.ItemsByIDOptions) (*stream.ItemsByID, error) {
+	return stream.GetItemsByID(ctx, c.rc, ids, opts)
+}
+
+// GetStreamPreferences calls stream.GetStreamPreferences.
+func (c *Client) GetStreamPreferences(ctx context.Context) (*stream.StreamPreferenceList, error) {
+	return stream.GetStreamPreferences(ctx, c.rc)
+}
+
+// SetStreamPreference calls stream.SetStreamPreference.
+func (c *Client) SetStreamPreference(ctx context.Context, streamID stream.StreamID, key, value string) error {
+	return stream.SetStreamPreference(ctx, c.rc, streamID, key, value)
+}
+
+// MarkAllAsRead calls stream.MarkAllAsReadContext.
+func (c *Client) MarkAllAsRead(ctx context.Context, params map[string]string) error {
+	return stream.MarkAllAsReadContext(ctx, c.rc, params)
+}
+
+// MarkStreamAsRead calls stream.MarkStreamAsRead.
+func (c *Client) MarkStreamAsRead(ctx context.Context, streamID stream.StreamID, before time.Time) error {
+	return stream.MarkStreamAsRead(ctx, c.rc, streamID, before)
+}
+
+// ResolveStreamID replaces the "-" of a user stream with the current
+// user's ID, which is looked up once per Client.
+func (c *Client) ResolveStreamID(ctx context.Context, streamID stream.StreamID) (stream.StreamID, error) {
+	return c.resolver.Resolve(ctx, streamID)
+}
+
+// QuickAddSubscription calls subscription.QuickAddSubscriptionContext.
+func (c *Client) QuickAddSubscription(ctx context.Context, params map[string]string) (*subscription.QuickAdd, error) {
+	return subscription.QuickAddSubscriptionContext(ctx, c.rc, params)
+}
+
+// QuickAddFeed calls subscription.QuickAddFeed.
+func (c *Client) QuickAddFeed(ctx context.Context, feed stream.StreamID) (*subscription.QuickAdd, error) {
+	return subscription.QuickAddFeed(ctx, c.rc, feed)
+}
+
+// EditSubscription calls subscription.EditSubscriptionContext.
+func (c *Client) EditSubscription(ctx context.Context, params map[string]string) error {
+	return subscription.EditSubscriptionContext(ctx, c.rc, params)
+}
+
+// EditSubscriptionWithOptions calls subscription.EditSubscriptionWithOptions.
+func (c *Client) EditSubscriptionWithOptions(ctx context.Context, opts *subscription.EditOptions) error {
+	return subscription.EditSubscriptionWithOptions(ctx, c.rc, opts)
+}
+
+// GetSubscriptionList calls subscription.GetSubscriptionListContext.
+func (c *Client) GetSubscriptionList(ctx context.Context) (*subscription.SubscriptionList, error) {
+	return subscription.GetSubscriptionListContext(ctx, c.rc)
+}
+
+// GetUnreadCounters calls subscription.GetUnreadCountersContext.
+func (c *Client) GetUnreadCounters(ctx context.Context) (*subscription.UnreadCounters, error) {
+	return subscription.GetUnreadCountersContext(ctx, c.rc)
+}
+
+// GetTagList calls tags.GetTagListContext.
+func (c *Client) GetTagList(ctx context.Context) (*tags.TagFolderList, error) {
+	return tags.GetTagListContext(ctx, c.rc)
+}
+
+// GetTagListWithOptions calls tags.GetTagListWithOptions.
+func (c *Client) GetTagListWithOptions(ctx context.Context, opts *tags.TagListOptions) (*tags.TagFolderList, error) {
+	return tags.GetTagListWithOptions(ctx, c.rc, opts)
+}
+
+// RenameLabel calls tags.RenameLabel.
+func (c *Client) RenameLabel(ctx context.Context, label, newLabel stream.StreamID) error {
+	return tags.RenameLabel(ctx, c.rc, label, newLabel)
+}
+
+// RenameTag calls tags.RenameTagContext.
+func (c *Client) RenameTag(ctx context.Context, params map[string]string) error {
+	return tags.RenameTagContext(ctx, c.rc, params)
+}
+
+// EditTag calls tags.EditTagContext.
+func (c *Client) EditTag(ctx context.Context, params map[string]string) error {
+	return tags.EditTagContext(ctx, c.rc, params)
+}
+
+// DeleteTag calls tags.DeleteTag.
+func (c *Client) DeleteTag(ctx context.Context, label stream.StreamID, opts *tags.DeleteOptions) (*tags.DeleteReport, error) {
+	return tags.DeleteTag(ctx, c.rc, label, opts)
+}
+
+// EditItemTags calls tags.EditItemTags.
+func (c *Client) EditItemTags(ctx context.Context, add, remove []stream.StreamID, ids []string, opts *tags.EditOptions) error {
+	return tags.EditItemTags(ctx, c.rc, add, remove, ids, opts)
+}
+
+// MarkRead calls tags.MarkRead.
+func (c *Client) MarkRead(ctx context.Context, ids ...string) error {
+	return tags.MarkRead(ctx, c.rc, ids...)
+}
+
+// MarkUnread calls tags.MarkUnread.
+func (c *Client) MarkUnread(ctx context.Context, ids ...string) error {
+	return tags.MarkUnread(ctx, c.rc, ids...)
+}
+
+// Star calls tags.Star.
+func (c *Client) Star(ctx context.Context, ids ...string) error {
+	return tags.Star(ctx, c.rc, ids...)
+}
+
+// Unstar calls tags.Unstar.
+func (c *Client) Unstar(ctx context.Context, ids ...string) error {
+	return tags.Unstar(ctx, c.rc, ids...)
+}
+
+// AddLabel calls tags.AddLabel.
+func (c *Client) AddLabel(ctx context.Context, label stream.StreamID, ids ...string) error {
+	return tags.AddLabel(ctx, c.rc, label, ids...)
+}
+
+// RemoveLabel calls tags.RemoveLabel.
+func (c *Client) RemoveLabel(ctx context.Context, label stream.StreamID, ids ...string) error {
+	return tags.RemoveLabel(ctx, c.rc, label, ids...)
+}
+
+// GetUserInfo calls userinfo.GetUserInfoContext.
+func (c *Client) GetUserInfo(ctx context.Context) (*userinfo.UserInfo, error) {
+	return userinfo.GetUserInfoContext(ctx, c.rc)
+}
@@ -0,0 +1,85 @@
+package inoreader
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+func TestClientBaseURL(t *testing.T) {
+	var gotPath, gotUA string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotPath = r.URL.Path
+		gotUA = r.UserAgent()
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"userId": "1005869311", "userName": "hyperreal"}`))
+	}))
+	defer srv.Close()
+
+	c := NewClient(WithBaseURL(srv.URL+"/reader/api/0"), WithUserAgent("go-inoreader-test"))
+
+	userinfo, err := c.GetUserInfo(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if gotPath != "/reader/api/0/user-info" {
+		t.Fatalf("requested %q, want /reader/api/0/user-info", gotPath)
+	}
+
+	if gotUA != "go-inoreader-test" {
+		t.Fatalf("User-Agent %q, want go-inoreader-test", gotUA)
+	}
+
+	if userinfo.UserName != "hyperreal" {
+		t.Fatalf("UserName %q, want hyperreal", userinfo.UserName)
+	}
+}
+
+func TestClientAPIError(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusServiceUnavailable)
+		w.Write([]byte("<html>Down for maintenance</html>"))
+	}))
+	defer srv.Close()
+
+	c := NewClient(WithBaseURL(srv.URL))
+
+	if err := c.EditTag(context.Background(), map[string]string{"a": "user/-/state/com.google/read", "i": "1"}); !IsServerError(err) {
+		t.Fatalf("EditTag error = %v, want a 503 APIError", err)
+	}
+
+	if _, err := c.GetSubscriptionList(context.Background()); !IsServerError(err) {
+		t.Fatalf("GetSubscriptionList error = %v, want a 503 APIError", err)
+	}
+}
+
+func TestClientContext(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		select {
+		case <-r.Context().Done():
+		case <-time.After(5 * time.Second):
+		}
+	}))
+	defer srv.Close()
+
+	c := NewClient(WithBaseURL(srv.URL))
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+
+	if _, err := c.GetUserInfo(ctx); !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("GetUserInfo error = %v, want %v", err, context.DeadlineExceeded)
+	}
+
+	ctx, cancel = context.WithCancel(context.Background())
+	cancel()
+
+	if _, err := c.GetSubscriptionList(ctx); !errors.Is(err, context.Canceled) {
+		t.Fatalf("GetSubscriptionList error = %v, want %v", err, context.Canceled)
+	}
+}
@@ -4,13 +4,15 @@ import (
 	"context"
 	"crypto/rand"
 	"encoding/base64"
-	"fmt"
 	"html/template"
 	"log"
 	"net/http"
+	"os"
 	"time"
 
 	"github.com/go-resty/resty/v2"
+	"github.com/hyperreal64/go-inoreader/api"
+	"github.com/pkg/errors"
 	"golang.org/x/oauth2"
 )
 
@@ -23,13 +25,14 @@ var scopes = []string{"read", "write"}
 
 const (
 	redirectURL          string = "http://localhost:8081/oauth/redirect"
+	defaultLoginPort     int    = 8081
 	authURL              string = "https://www.inoreader.com/oauth2/auth?"
 	tokenURL             string = "https://www.inoreader.com/oauth2/token"
 	authResponseTemplate        = `<!DOCTYPE html>
 <html lang="en">
 <head>
 <meta charset="utf-8">
-<title>Done</title>
+<title>{{ .Title }}</title>
 </head>
 <body>
 <h1>{{ .Title }}</h1>
@@ -40,24 +43,50 @@ const (
 `
 )
 
+// Returns a random, URL-safe string built from `n` random bytes.
+func generateRandomString(n int) (string, error) {
+
+	b := make([]byte, n)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+
+	return base64.RawURLEncoding.EncodeToString(b), nil
+}
+
 // Generates an Oauth state cookie and returns it as a string.
 func generateOauthStateCookie(w http.ResponseWriter) (string, error) {
 
 	var expiration = time.Now().Add(20 * time.Minute)
 
-	b := make([]byte, 16)
-	_, err := rand.Read(b)
+	state, err := generateRandomString(16)
 	if err != nil {
 		return "", err
 	}
 
-	state := base64.URLEncoding.EncodeToString(b)
 	cookie := http.Cookie{Name: "oauthstate", Value: state, Expires: expiration}
 	http.SetCookie(w, &cookie)
 
 	return state, nil
 }
 
+// Generates a PKCE code verifier, stores it in a cookie for the callback and
+// returns it as a string.
+func generatePKCEVerifierCookie(w http.ResponseWriter) (string, error) {
+
+	var expiration = time.Now().Add(20 * time.Minute)
+
+	verifier, err := generatePKCEVerifier()
+	if err != nil {
+		return "", err
+	}
+
+	cookie := http.Cookie{Name: "oauthverifier", Value: verifier, Expires: expiration, HttpOnly: true}
+	http.SetCookie(w, &cookie)
+
+	return verifier, nil
+}
+
 // Loads go-inoreader.json configuration file into a oauth2.Config struct
 // alongside Oauth config data.
 func (c *config) getOauthConf() {
@@ -79,98 +108,214 @@ func (c *config) getOauthConf() {
 // Sends initial request to the API to start Oauth flow using
 // the api_key and app_id from go-inoreader.json. Handles user
 // login in browser.
-func (c *config) handleInoreaderLogin(w http.ResponseWriter, r *http.Request) {
+func (s *loginServer) handleInoreaderLogin(w http.ResponseWriter, r *http.Request) {
 
 	oauthState, err := generateOauthStateCookie(w)
 	if err != nil {
-		fmt.Println("Error: ", err)
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	verifier, err := generatePKCEVerifierCookie(w)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
 		return
 	}
 
-	url := c.OAuth2Conf.AuthCodeURL(oauthState)
+	url := s.cfg.OAuth2Conf.AuthCodeURL(oauthState, pkceChallengeOptions(verifier)...)
 	http.Redirect(w, r, url, http.StatusTemporaryRedirect)
 }
 
-// Receives callback from Inoreader API, verifies Oauth state value, converts
-// auth code into a token, and writes token info to go-inoreader.json. After
-// successful exchange, user is redirected to callback URL, which is a page
-// that displays the authResponseTemplate.
-func (c *config) handleInoreaderCallback(w http.ResponseWriter, r *http.Request) {
+// Receives callback from Inoreader API, verifies Oauth state value and
+// converts auth code into a token. The result is handed to Login, and the
+// user is shown a page that displays the authResponseTemplate, describing
+// what went wrong if the login failed.
+func (s *loginServer) handleInoreaderCallback(w http.ResponseWriter, r *http.Request) {
 
-	oauthState, _ := r.Cookie("oauthstate")
+	token, err := s.exchangeCallback(r)
+	if err != nil {
+		serveTemplate(w, http.StatusBadRequest, "Login failed", callbackErrorMessage(err))
+		s.finish(nil, err)
+		return
+	}
+
+	serveTemplate(w, http.StatusOK, "Done", "You may close this page and return to go-inoreader in your terminal.")
+	s.finish(token, nil)
+}
+
+// Checks the provider's error parameters and the Oauth state cookie, then
+// exchanges the auth code for a token. Failures are returned as
+// *CallbackError.
+func (s *loginServer) exchangeCallback(r *http.Request) (*oauth2.Token, error) {
+
+	if code := r.FormValue("error"); code != "" {
+		return nil, providerError(code, r.FormValue("error_description"))
+	}
+
+	oauthState, err := r.Cookie("oauthstate")
+	if err != nil || oauthState.Value == "" {
+		return nil, &CallbackError{Reason: ErrStateExpired}
+	}
 
 	if r.FormValue("state") != oauthState.Value {
-		log.Println("Invalid OAuth state")
-		return
+		return nil, &CallbackError{Reason: ErrStateMismatch}
+	}
+
+	code := r.FormValue("code")
+	if code == "" {
+		return nil, &CallbackError{Reason: ErrMissingAuthorization}
+	}
+
+	var opts []oauth2.AuthCodeOption
+	if verifier, err := r.Cookie("oauthverifier"); err == nil {
+		opts = append(opts, pkceVerifierOption(verifier.Value))
 	}
 
-	token, err := c.OAuth2Conf.Exchange(context.Background(), r.FormValue("code"))
+	token, err := s.cfg.OAuth2Conf.Exchange(s.ctx, code, opts...)
 	if err != nil {
-		log.Println(err)
+		return nil, &CallbackError{Reason: ErrExchangeFailed, Err: err}
 	}
 
-	if err := c.writeCfgFile(getCfgFilePath(), token); err != nil {
-		log.Println(err)
-		return
+	return token, nil
+}
+
+// Returns the message shown on the result page for a failed callback.
+func callbackErrorMessage(err error) string {
+
+	switch {
+	case errors.Is(err, ErrAccessDenied):
+		return "Access to Inoreader was denied. Start the login again from go-inoreader if this was a mistake."
+	case errors.Is(err, ErrStateExpired):
+		return "The login session expired. Start the login again from go-inoreader."
+	case errors.Is(err, ErrStateMismatch):
+		return "The login response did not match this login session. Start the login again from go-inoreader."
+	case errors.Is(err, ErrExchangeFailed):
+		return "Inoreader did not accept the authorization code. Check the app ID and app key, then try again."
+	default:
+		return err.Error()
+	}
+}
+
+// Takes a context.Context, loads credentials from `store`, and returns an
+// oauth2.TokenSource that refreshes the token when it expires and saves each
+// new token back to `store`.
+func Oauth2TokenSourceWithStore(ctx context.Context, store TokenStore) (oauth2.TokenSource, error) {
+
+	creds, err := store.Load()
+	if err != nil {
+		return nil, err
 	}
 
-	http.Redirect(w, r, "/go-inoreader", http.StatusTemporaryRedirect)
+	c := &config{Credentials: *creds}
+	if err := validateConfig(c); err != nil {
+		return nil, err
+	}
+
+	return c.tokenSource(ctx, store), nil
 }
 
-// Takes a context.Context, loads go-inoreader.json config with token info,
-// initializes and returns a resty.Client with context and token data.
-func Oauth2RestyClient(ctx context.Context) *resty.Client {
+// Takes a context.Context, loads credentials from `store`, initializes and
+// returns an *http.Client that authorizes requests with the token data.
+// Refreshed tokens are saved to `store`.
+func Oauth2HTTPClientWithStore(ctx context.Context, store TokenStore) (*http.Client, error) {
 
-	c, err := loadConfig(getCfgFilePath())
+	ts, err := Oauth2TokenSourceWithStore(ctx, store)
 	if err != nil {
-		log.Println(err)
+		return nil, err
+	}
+
+	return oauth2.NewClient(ctx, ts), nil
+}
+
+// Takes a context.Context, loads credentials from `store`, initializes and
+// returns a resty.Client with context and token data.
+func Oauth2RestyClientWithStore(ctx context.Context, store TokenStore) (*resty.Client, error) {
+
+	hc, err := Oauth2HTTPClientWithStore(ctx, store)
+	if err != nil {
+		return nil, err
+	}
+
+	return resty.NewWithClient(hc).SetHostURL(api.DefaultBaseURL), nil
+}
+
+// Takes a context.Context and the name of a profile in go-inoreader.json,
+// initializes and returns a resty.Client with that profile's token data.
+// Refreshed tokens are saved to the same profile.
+func Oauth2RestyClientForProfile(ctx context.Context, profile string) (*resty.Client, error) {
+	return Oauth2RestyClientWithStore(ctx, NewProfileStore("", profile))
+}
+
+// Takes a context.Context, loads go-inoreader.json config with token info,
+// and returns an oauth2.TokenSource that refreshes the token when it expires
+// and writes each new token back to go-inoreader.json. If the config cannot
+// be loaded, the returned source fails every call with that error (a
+// *ConfigError when fields are missing); use Oauth2TokenSourceWithStore to
+// get the error up front.
+func Oauth2TokenSource(ctx context.Context) oauth2.TokenSource {
+
+	ts, err := Oauth2TokenSourceWithStore(ctx, NewFileStore(""))
+	if err != nil {
+		return errTokenSource{err: err}
 	}
 
-	token := new(oauth2.Token)
-	token.AccessToken = c.AccessToken
-	token.RefreshToken = c.RefreshToken
-	token.TokenType = c.TokenType
-	token.Expiry = c.Expiry
+	return ts
+}
 
-	return resty.NewWithClient(c.OAuth2Conf.Client(ctx, token))
+// Takes a context.Context, loads go-inoreader.json config with token info,
+// initializes and returns an *http.Client that authorizes requests with the
+// token data. Refreshed tokens are persisted to go-inoreader.json.
+//
+// Deprecated: a config that cannot be loaded only fails the first request;
+// use NewOauth2HTTPClient to get the error at construction.
+func Oauth2HTTPClient(ctx context.Context) *http.Client {
+	return oauth2.NewClient(ctx, Oauth2TokenSource(ctx))
 }
 
-// Serves authResponseTemplate
-func serveTemplate(w http.ResponseWriter, r *http.Request) {
+// Takes a context.Context, loads go-inoreader.json config with token info,
+// initializes and returns a resty.Client with context and token data.
+//
+// Deprecated: a config that cannot be loaded only fails the first request;
+// use NewOauth2RestyClient to get the error at construction.
+func Oauth2RestyClient(ctx context.Context) *resty.Client {
+	return resty.NewWithClient(Oauth2HTTPClient(ctx)).SetHostURL(api.DefaultBaseURL)
+}
 
-	templateMsg := &authTemplate{Title: "Done", Message: "You may close this page and return to go-inoreader in your terminal."}
+// Takes a context.Context, loads go-inoreader.json config with token info,
+// and returns an *http.Client like Oauth2HTTPClient. A config that cannot
+// be loaded or validated is reported here, as a *ConfigError when fields
+// are missing.
+func NewOauth2HTTPClient(ctx context.Context) (*http.Client, error) {
+	return Oauth2HTTPClientWithStore(ctx, NewFileStore(""))
+}
+
+// Takes a context.Context, loads go-inoreader.json config with token info,
+// and returns a resty.Client like Oauth2RestyClient. A config that cannot
+// be loaded or validated is reported here, as a *ConfigError when fields
+// are missing.
+func NewOauth2RestyClient(ctx context.Context) (*resty.Client, error) {
+	return Oauth2RestyClientWithStore(ctx, NewFileStore(""))
+}
+
+// Serves authResponseTemplate with the given status code, title and message.
+func serveTemplate(w http.ResponseWriter, status int, title string, message string) {
+
+	templateMsg := &authTemplate{Title: title, Message: message}
 	var t = template.Must(template.New("authResponse").Parse(authResponseTemplate))
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	w.WriteHeader(status)
 	if err := t.Execute(w, templateMsg); err != nil {
-		http.Error(w, err.Error(), http.StatusInternalServerError)
+		log.Println(err)
 	}
 }
 
-// Initiates OAuth flow
+// Initiates OAuth flow with the default LoginOptions and writes the token to
+// go-inoreader.json. Use Login to handle errors instead of exiting.
 func Init() {
 
-	ctx, cancel := context.WithCancel(context.Background())
-	config, err := loadConfig(getCfgFilePath())
-	if err != nil {
+	if _, err := Login(context.Background(), &LoginOptions{Out: os.Stderr}); err != nil {
 		log.Fatalln(err)
 	}
-	config.getOauthConf()
-
-	http.HandleFunc("/", config.handleInoreaderLogin)
-	http.HandleFunc("/oauth/redirect", config.handleInoreaderCallback)
-	http.HandleFunc("/go-inoreader", func(w http.ResponseWriter, r *http.Request) {
-		serveTemplate(w, r)
-		defer cancel()
-	})
-
-	// TODO: This can be improved
-	srv := &http.Server{Addr: ":8081"}
-	log.Println("Server listening on http://localhost:8081")
-	go func() {
-		if err := srv.ListenAndServe(); err != nil {
-			log.Printf("Server error: %s", err.Error())
-		}
-	}()
-	<-ctx.Done()
 
 	log.Println("Done")
 }
@@ -1,52 +1,89 @@
 package config
 
 import (
-	"encoding/json"
+	"io/ioutil"
 	"os"
 	"path"
+	"path/filepath"
 	"runtime"
-	"strings"
 	"time"
 
 	"github.com/pkg/errors"
 	"golang.org/x/oauth2"
 )
 
-// Config file values
-type config struct {
+// Credentials are the app credentials and OAuth token data kept by a
+// TokenStore.
+type Credentials struct {
 	AppID        string    `json:"app_id"`
 	AppKey       string    `json:"app_key"`
 	AccessToken  string    `json:"access_token"`
 	RefreshToken string    `json:"refresh_token"`
 	TokenType    string    `json:"token_type,omitempty"`
 	Expiry       time.Time `json:"expiry,omitempty"`
-	OAuth2Conf   *oauth2.Config
 }
 
-// Loads configuration file located at `filePath` into a *config struct.
-func loadConfig(filePath string) (cfg *config, err error) {
+// Token returns the OAuth token held in the credentials.
+func (c *Credentials) Token() *oauth2.Token {
 
-	// Check if file exists
-	if _, err := os.Stat(filePath); os.IsNotExist(err) {
-		return nil, errors.Wrapf(err, "Config file does not exist: %s", filePath)
+	return &oauth2.Token{
+		AccessToken:  c.AccessToken,
+		RefreshToken: c.RefreshToken,
+		TokenType:    c.TokenType,
+		Expiry:       c.Expiry,
 	}
+}
+
+// SetToken replaces the OAuth token held in the credentials.
+func (c *Credentials) SetToken(token *oauth2.Token) {
+
+	c.AccessToken = token.AccessToken
+	c.RefreshToken = token.RefreshToken
+	c.TokenType = token.TokenType
+	c.Expiry = token.Expiry
+}
+
+// Config file values of one profile
+type config struct {
+	Credentials
+	OAuth2Conf *oauth2.Config `json:"-"`
+	profile    string
+}
+
+// Loads the current profile of the configuration file located at `filePath`
+// into a *config struct.
+func loadConfig(filePath string) (cfg *config, err error) {
+	return loadProfile(filePath, "")
+}
 
-	configFile, err := os.ReadFile(filePath)
+// Loads the profile `profile` of the configuration file located at
+// `filePath` into a *config struct. An empty name means the current profile.
+func loadProfile(filePath string, profile string) (cfg *config, err error) {
+
+	f, err := readCfgFile(filePath)
 	if err != nil {
-		return nil, errors.Wrapf(err, "An error occurred while trying to read config file: %s", filePath)
+		return nil, err
 	}
 
-	if err := json.Unmarshal(configFile, &cfg); err != nil {
-		return nil, errors.Wrapf(err, "Unable to unmarshal JSON content: %s", filePath)
+	name := f.resolve(profile)
+	creds, ok := f.Profiles[name]
+	if !ok {
+		return nil, errors.Wrapf(ErrNoSuchProfile, "%s in %s", name, filePath)
 	}
 
-	validateConfig(cfg)
+	cfg = &config{Credentials: *creds, profile: name}
+	if err := validateConfig(cfg); err != nil {
+		err.Path = filePath
+		err.Profile = name
+		return nil, err
+	}
 
 	return cfg, nil
 }
 
-// Verifies that config values are valid.
-func validateConfig(c *config) error {
+// Verifies that config values are valid. Returns a *ConfigError listing the
+// missing fields, or nil.
+func validateConfig(c *config) *ConfigError {
 
 	var fieldsMissing []string
 	if c.AppID == "" {
@@ -58,43 +95,81 @@ func validateConfig(c *config) error {
 	}
 
 	if len(fieldsMissing) > 0 {
-		errMsg := "The following fields appear missing from config:"
-		return errors.Wrap(errors.New(errMsg), strings.Join(fieldsMissing, "\n"))
+		return &ConfigError{Missing: fieldsMissing}
 	}
 
 	return nil
 }
 
 // Mutates *config struct state, adds Oauth data, and writes the resulting struct
-// data to the go-inoreader.json config file.
+// data to its profile in the go-inoreader.json config file. Other profiles in
+// the file are left untouched.
 func (c *config) writeCfgFile(filePath string, oauth2Resp *oauth2.Token) error {
 
-	cfg := &config{
-		AppID:        c.AppID,
-		AppKey:       c.AppKey,
-		AccessToken:  oauth2Resp.AccessToken,
-		RefreshToken: oauth2Resp.RefreshToken,
-		TokenType:    oauth2Resp.TokenType,
-		Expiry:       oauth2Resp.Expiry,
+	cfg := &config{Credentials: c.Credentials}
+	cfg.SetToken(oauth2Resp)
+
+	return updateCfgFile(filePath, func(f *cfgFile) error {
+		name := f.resolve(c.profile)
+		f.Profiles[name] = &cfg.Credentials
+
+		if f.CurrentProfile == "" {
+			f.CurrentProfile = name
+		}
+
+		return nil
+	})
+}
+
+// Writes data to a temporary file next to `filePath` and renames it into
+// place, so readers never see a partially written config file.
+func writeFileAtomic(filePath string, data []byte, perm os.FileMode) error {
+
+	if err := os.MkdirAll(filepath.Dir(filePath), 0700); err != nil {
+		return err
 	}
 
-	jsonData, err := json.MarshalIndent(&cfg, "", "  ")
+	tmp, err := ioutil.TempFile(filepath.Dir(filePath), filepath.Base(filePath)+".tmp*")
 	if err != nil {
-		return errors.Wrapf(err, "Unable to parse JSON data: %#v", cfg)
+		return err
 	}
+	tmpName := tmp.Name()
+	defer os.Remove(tmpName)
 
-	if err := os.WriteFile(filePath, jsonData, 0600); err != nil {
-		return errors.Wrapf(err, "Unable to write JSON data to config file: %v", filePath)
+	if err := tmp.Chmod(perm); err != nil {
+		tmp.Close()
+		return err
 	}
 
-	return nil
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return err
+	}
+
+	if err := tmp.Sync(); err != nil {
+		tmp.Close()
+		return err
+	}
+
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+
+	return os.Rename(tmpName, filePath)
 }
 
-// Get the path of the configuration file
-// On Unix/Linux: $XDG_DATA_HOME/go-inoreader.json
-// On Windows: %APPDATA%\go-inoreader.json
+// Get the path of the configuration file, as resolved by Path.
 func getCfgFilePath() string {
 
+	filePath, _ := Path()
+	return filePath
+}
+
+// Get the legacy path of the configuration file
+// On Unix/Linux: ~/.local/share/go-inoreader.json
+// On Windows: %APPDATA%\go-inoreader.json
+func legacyCfgFilePath() string {
+
 	homeDir, _ := os.UserHomeDir()
 	var fileName string
 
@@ -1,10 +1,33 @@
 package config
 
 import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
 	"testing"
 )
 
+// Sets the environment variable `key` for the duration of the test.
+func setenv(t *testing.T, key string, value string) {
+	old, ok := os.LookupEnv(key)
+	os.Setenv(key, value)
+	t.Cleanup(func() {
+		if ok {
+			os.Setenv(key, old)
+		} else {
+			os.Unsetenv(key)
+		}
+	})
+}
+
 func TestLoadConfig(t *testing.T) {
+	filePath := filepath.Join(t.TempDir(), "go-inoreader.json")
+	data := `{"app_id": "1000000000", "app_key": "secret", "access_token": "access", "refresh_token": "refresh"}`
+	if err := ioutil.WriteFile(filePath, []byte(data), 0600); err != nil {
+		t.Fatal(err)
+	}
+	setenv(t, EnvConfigPath, filePath)
+
 	var cfg = &config{}
 
 	cfg, err := loadConfig(getCfgFilePath())
@@ -0,0 +1,215 @@
+package config
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"runtime"
+	"strings"
+	"time"
+)
+
+// CheckStatus is the outcome of one Diagnose check.
+type CheckStatus string
+
+// Outcomes of a Diagnose check.
+const (
+	CheckOK      CheckStatus = "ok"
+	CheckWarn    CheckStatus = "warn"
+	CheckFail    CheckStatus = "fail"
+	CheckSkipped CheckStatus = "skipped"
+)
+
+// Names of the Diagnose checks, in the order they run.
+const (
+	CheckFile        = "file"
+	CheckPermissions = "permissions"
+	CheckJSON        = "json"
+	CheckFields      = "fields"
+	CheckToken       = "token"
+	CheckExpiry      = "expiry"
+	CheckRefresh     = "refresh"
+)
+
+// Check is the result of one Diagnose check.
+type Check struct {
+	Name    string      `json:"name"`
+	Status  CheckStatus `json:"status"`
+	Message string      `json:"message"`
+}
+
+// Report is the structured result of Diagnose.
+type Report struct {
+	Path    string     `json:"path"`
+	Source  PathSource `json:"source"`
+	Profile string     `json:"profile"`
+	Checks  []Check    `json:"checks"`
+}
+
+// OK reports whether no check failed.
+func (r *Report) OK() bool {
+
+	for _, c := range r.Checks {
+		if c.Status == CheckFail {
+			return false
+		}
+	}
+
+	return true
+}
+
+// Check returns the check named `name`, or nil if it did not run.
+func (r *Report) Check(name string) *Check {
+
+	for i := range r.Checks {
+		if r.Checks[i].Name == name {
+			return &r.Checks[i]
+		}
+	}
+
+	return nil
+}
+
+func (r *Report) add(name string, status CheckStatus, format string, args ...interface{}) {
+	r.Checks = append(r.Checks, Check{Name: name, Status: status, Message: fmt.Sprintf(format, args...)})
+}
+
+// Marks every check in `names` that has not run as skipped.
+func (r *Report) skip(reason string, names ...string) {
+
+	for _, name := range names {
+		if r.Check(name) == nil {
+			r.add(name, CheckSkipped, "%s", reason)
+		}
+	}
+}
+
+// DiagnoseOptions configures Diagnose.
+type DiagnoseOptions struct {
+	// Path of the config file to check. Defaults to the file chosen by
+	// Path.
+	Path string
+
+	// Profile to check. Defaults to the current profile.
+	Profile string
+
+	// CheckRefresh exchanges the refresh token at the token endpoint to make
+	// sure it still works. The new token is saved to the profile, since
+	// Inoreader may rotate the refresh token.
+	CheckRefresh bool
+
+	// TokenURL overrides the token endpoint used by CheckRefresh.
+	TokenURL string
+}
+
+// Diagnose checks the config file and reports, check by check, whether it
+// exists, is readable only by its owner (0600), holds valid JSON with the
+// required fields, has a token and whether that token has expired. With
+// opts.CheckRefresh it also tries the refresh token against the token
+// endpoint. Diagnose never fails itself; problems are in the Report.
+func Diagnose(ctx context.Context, opts *DiagnoseOptions) *Report {
+
+	var o DiagnoseOptions
+	if opts != nil {
+		o = *opts
+	}
+
+	report := &Report{Path: o.Path, Source: PathExplicit}
+	if report.Path == "" {
+		report.Path, report.Source = Path()
+	}
+
+	allChecks := []string{CheckFile, CheckPermissions, CheckJSON, CheckFields, CheckToken, CheckExpiry, CheckRefresh}
+
+	info, err := os.Stat(report.Path)
+	if err != nil {
+		report.add(CheckFile, CheckFail, "%v", err)
+		report.skip("no config file", allChecks...)
+		return report
+	}
+	report.add(CheckFile, CheckOK, "%s", report.Path)
+
+	switch mode := info.Mode().Perm(); {
+	case runtime.GOOS == "windows":
+		report.add(CheckPermissions, CheckSkipped, "not checked on Windows")
+	case mode&0077 != 0:
+		report.add(CheckPermissions, CheckWarn, "mode is %04o, want 0600", mode)
+	default:
+		report.add(CheckPermissions, CheckOK, "mode is %04o", mode)
+	}
+
+	f, err := readCfgFile(report.Path)
+	if err != nil {
+		report.add(CheckJSON, CheckFail, "%v", err)
+		report.skip("config file is not valid JSON", allChecks...)
+		return report
+	}
+	report.add(CheckJSON, CheckOK, "valid JSON with %d profile(s)", len(f.Profiles))
+
+	report.Profile = f.resolve(o.Profile)
+	cfg, err := loadProfile(report.Path, report.Profile)
+	if err != nil {
+		report.add(CheckFields, CheckFail, "%v", err)
+		report.skip("required fields are missing", allChecks...)
+		return report
+	}
+	report.add(CheckFields, CheckOK, "app_id and app_key are set")
+
+	var missing []string
+	if cfg.AccessToken == "" {
+		missing = append(missing, "access_token")
+	}
+	if cfg.RefreshToken == "" {
+		missing = append(missing, "refresh_token")
+	}
+
+	switch {
+	case len(missing) == 2:
+		report.add(CheckToken, CheckFail, "no token; log in with config.Login")
+	case len(missing) == 1:
+		report.add(CheckToken, CheckWarn, "%s is missing", strings.Join(missing, ", "))
+	default:
+		report.add(CheckToken, CheckOK, "access and refresh tokens are set")
+	}
+
+	switch {
+	case cfg.Expiry.IsZero():
+		report.add(CheckExpiry, CheckWarn, "token has no expiry")
+	case cfg.Expiry.Before(time.Now()):
+		report.add(CheckExpiry, CheckWarn, "access token expired at %s; it is refreshed on the next request", cfg.Expiry.Format(time.RFC3339))
+	default:
+		report.add(CheckExpiry, CheckOK, "access token expires at %s", cfg.Expiry.Format(time.RFC3339))
+	}
+
+	switch {
+	case !o.CheckRefresh:
+		report.add(CheckRefresh, CheckSkipped, "not requested")
+	case cfg.RefreshToken == "":
+		report.add(CheckRefresh, CheckFail, "no refresh token")
+	default:
+		if err := cfg.checkRefresh(ctx, NewProfileStore(report.Path, report.Profile), o.TokenURL); err != nil {
+			report.add(CheckRefresh, CheckFail, "%v", err)
+		} else {
+			report.add(CheckRefresh, CheckOK, "refresh token was accepted")
+		}
+	}
+
+	return report
+}
+
+// Forces a refresh of the token through the persisting token source, so a
+// rotated refresh token is saved to `store`.
+func (c *config) checkRefresh(ctx context.Context, store TokenStore, tokenURL string) error {
+
+	c.getOauthConf()
+	if tokenURL != "" {
+		c.OAuth2Conf.Endpoint.TokenURL = tokenURL
+	}
+
+	expired := *c
+	expired.AccessToken = ""
+	expired.Expiry = time.Now().Add(-time.Hour)
+
+	_, err := expired.tokenSource(ctx, store).Token()
+	return err
+}
@@ -0,0 +1,88 @@
+package config
+
+import (
+	"context"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"testing"
+
+	"github.com/pkg/errors"
+)
+
+func TestLoadConfigMissingFields(t *testing.T) {
+	filePath := filepath.Join(t.TempDir(), "go-inoreader.json")
+	if err := ioutil.WriteFile(filePath, []byte(`{"app_id": "1000000000"}`), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	_, err := loadConfig(filePath)
+
+	var cfgErr *ConfigError
+	if !errors.As(err, &cfgErr) {
+		t.Fatalf("loadConfig error = %v, want a *ConfigError", err)
+	}
+
+	if len(cfgErr.Missing) != 1 || cfgErr.Missing[0] != "app_key" {
+		t.Fatalf("Missing = %v, want [app_key]", cfgErr.Missing)
+	}
+
+	setenv(t, EnvConfigPath, filePath)
+	if rc, err := NewOauth2RestyClient(context.Background()); rc != nil || !errors.As(err, &cfgErr) {
+		t.Fatalf("NewOauth2RestyClient error = %v, want a *ConfigError", err)
+	}
+
+	rc := Oauth2RestyClient(context.Background())
+	if _, err := rc.R().Get("user-info"); !errors.As(err, &cfgErr) {
+		t.Fatalf("request error = %v, want a *ConfigError", err)
+	}
+}
+
+func TestDiagnose(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"access_token": "new-access", "refresh_token": "rotated-refresh", "token_type": "Bearer", "expires_in": 3600}`))
+	}))
+	defer srv.Close()
+
+	filePath := filepath.Join(t.TempDir(), "go-inoreader.json")
+	data := `{"app_id": "1000000000", "app_key": "secret", "access_token": "access", "refresh_token": "refresh", "expiry": "2001-01-01T00:00:00Z"}`
+	if err := ioutil.WriteFile(filePath, []byte(data), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	report := Diagnose(context.Background(), &DiagnoseOptions{Path: filePath, CheckRefresh: true, TokenURL: srv.URL})
+	if !report.OK() {
+		t.Fatalf("report has failures: %#v", report.Checks)
+	}
+
+	want := map[string]CheckStatus{
+		CheckFile:        CheckOK,
+		CheckPermissions: CheckWarn,
+		CheckJSON:        CheckOK,
+		CheckFields:      CheckOK,
+		CheckToken:       CheckOK,
+		CheckExpiry:      CheckWarn,
+		CheckRefresh:     CheckOK,
+	}
+	for name, status := range want {
+		if c := report.Check(name); c == nil || c.Status != status {
+			t.Fatalf("check %s = %#v, want status %s", name, c, status)
+		}
+	}
+
+	saved, err := loadConfig(filePath)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if saved.RefreshToken != "rotated-refresh" {
+		t.Fatalf("RefreshToken %q after refresh check, want rotated-refresh", saved.RefreshToken)
+	}
+
+	report = Diagnose(context.Background(), &DiagnoseOptions{Path: filepath.Join(t.TempDir(), "missing.json")})
+	if report.OK() || report.Check(CheckFile).Status != CheckFail {
+		t.Fatalf("report for missing file: %#v", report.Checks)
+	}
+}
@@ -0,0 +1,146 @@
+package config
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/json"
+	"io/ioutil"
+	"os"
+
+	"github.com/pkg/errors"
+	"golang.org/x/crypto/scrypt"
+)
+
+// scrypt parameters used to derive the file key from the passphrase.
+const (
+	scryptN      = 1 << 15
+	scryptR      = 8
+	scryptP      = 1
+	scryptKeyLen = 32
+	saltLen      = 16
+)
+
+// ErrWrongPassphrase is returned when an encrypted config file cannot be
+// decrypted with the given passphrase.
+var ErrWrongPassphrase = errors.New("Unable to decrypt config file: wrong passphrase or corrupt file")
+
+// On-disk layout of an encrypted config file. The credentials are
+// encrypted with AES-256-GCM under a key derived from the passphrase with
+// scrypt.
+type encryptedFile struct {
+	Version    int    `json:"version"`
+	Salt       []byte `json:"salt"`
+	Nonce      []byte `json:"nonce"`
+	Ciphertext []byte `json:"ciphertext"`
+}
+
+// EncryptedFileStore keeps credentials in a passphrase-encrypted file.
+type EncryptedFileStore struct {
+	Path       string
+	passphrase []byte
+}
+
+// NewEncryptedFileStore returns an EncryptedFileStore for the file at
+// `filePath`, encrypted with `passphrase`.
+func NewEncryptedFileStore(filePath string, passphrase string) *EncryptedFileStore {
+	return &EncryptedFileStore{Path: filePath, passphrase: []byte(passphrase)}
+}
+
+// Returns the AES-GCM cipher for the key derived from `salt`.
+func (s *EncryptedFileStore) aead(salt []byte) (cipher.AEAD, error) {
+
+	key, err := scrypt.Key(s.passphrase, salt, scryptN, scryptR, scryptP, scryptKeyLen)
+	if err != nil {
+		return nil, err
+	}
+
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+
+	return cipher.NewGCM(block)
+}
+
+// Load decrypts the credentials from the file.
+func (s *EncryptedFileStore) Load() (*Credentials, error) {
+
+	data, err := ioutil.ReadFile(s.Path)
+	if err != nil {
+		return nil, errors.Wrapf(err, "An error occurred while trying to read config file: %s", s.Path)
+	}
+
+	var ef encryptedFile
+	if err := json.Unmarshal(data, &ef); err != nil {
+		return nil, errors.Wrapf(err, "Unable to unmarshal JSON content: %s", s.Path)
+	}
+
+	aead, err := s.aead(ef.Salt)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(ef.Nonce) != aead.NonceSize() {
+		return nil, ErrWrongPassphrase
+	}
+
+	plaintext, err := aead.Open(nil, ef.Nonce, ef.Ciphertext, nil)
+	if err != nil {
+		return nil, ErrWrongPassphrase
+	}
+
+	var creds Credentials
+	if err := json.Unmarshal(plaintext, &creds); err != nil {
+		return nil, errors.Wrapf(err, "Unable to unmarshal JSON content: %s", s.Path)
+	}
+
+	return &creds, nil
+}
+
+// Save encrypts `creds` with a fresh salt and nonce and writes them to the
+// file atomically.
+func (s *EncryptedFileStore) Save(creds *Credentials) error {
+
+	plaintext, err := json.Marshal(creds)
+	if err != nil {
+		return errors.Wrap(err, "Unable to parse JSON data")
+	}
+
+	ef := encryptedFile{Version: 1, Salt: make([]byte, saltLen)}
+	if _, err := rand.Read(ef.Salt); err != nil {
+		return err
+	}
+
+	aead, err := s.aead(ef.Salt)
+	if err != nil {
+		return err
+	}
+
+	ef.Nonce = make([]byte, aead.NonceSize())
+	if _, err := rand.Read(ef.Nonce); err != nil {
+		return err
+	}
+	ef.Ciphertext = aead.Seal(nil, ef.Nonce, plaintext, nil)
+
+	data, err := json.MarshalIndent(&ef, "", "  ")
+	if err != nil {
+		return errors.Wrap(err, "Unable to parse JSON data")
+	}
+
+	if err := writeFileAtomic(s.Path, data, 0600); err != nil {
+		return errors.Wrapf(err, "Unable to write JSON data to config file: %v", s.Path)
+	}
+
+	return nil
+}
+
+// Delete removes the file.
+func (s *EncryptedFileStore) Delete() error {
+
+	if err := os.Remove(s.Path); err != nil && !os.IsNotExist(err) {
+		return errors.Wrapf(err, "Unable to remove config file: %s", s.Path)
+	}
+
+	return nil
+}
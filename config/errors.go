@@ -0,0 +1,90 @@
+package config
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// Reasons a login can fail in the OAuth callback. Test for them with
+// errors.Is on the error returned by Login.
+var (
+	ErrAccessDenied         = errors.New("Authorization was denied")
+	ErrAuthorizationFailed  = errors.New("Authorization failed")
+	ErrStateMismatch        = errors.New("Invalid OAuth state")
+	ErrStateExpired         = errors.New("OAuth state expired or missing")
+	ErrExchangeFailed       = errors.New("Unable to exchange authorization code")
+	ErrMissingAuthorization = errors.New("Callback has no authorization code")
+)
+
+// CallbackError describes why the OAuth callback failed. Reason is one of the
+// Err* values above; Code and Description carry the provider's `error` and
+// `error_description` query parameters, if any.
+type CallbackError struct {
+	Reason      error
+	Code        string
+	Description string
+	Err         error
+}
+
+func (e *CallbackError) Error() string {
+
+	msg := e.Reason.Error()
+	if e.Code != "" {
+		msg = fmt.Sprintf("%s: %s", msg, e.Code)
+	}
+
+	if e.Description != "" {
+		msg = fmt.Sprintf("%s (%s)", msg, e.Description)
+	}
+
+	if e.Err != nil {
+		msg = fmt.Sprintf("%s: %v", msg, e.Err)
+	}
+
+	return msg
+}
+
+// Unwrap returns the underlying error, such as the token exchange failure.
+func (e *CallbackError) Unwrap() error {
+	return e.Err
+}
+
+// Is reports whether `target` is the Reason of the callback failure.
+func (e *CallbackError) Is(target error) bool {
+	return target == e.Reason
+}
+
+// Returns the *CallbackError for the provider's `error` query parameter.
+func providerError(code string, description string) *CallbackError {
+
+	reason := ErrAuthorizationFailed
+	if code == "access_denied" {
+		reason = ErrAccessDenied
+	}
+
+	return &CallbackError{Reason: reason, Code: code, Description: description}
+}
+
+// ConfigError is returned when the loaded credentials lack required fields.
+// Path and Profile are set when the credentials came from a config file.
+type ConfigError struct {
+	Path    string
+	Profile string
+	Missing []string
+}
+
+func (e *ConfigError) Error() string {
+
+	msg := "The following fields appear missing from config"
+	if e.Path != "" {
+		msg = fmt.Sprintf("%s %s", msg, e.Path)
+	}
+
+	if e.Profile != "" {
+		msg = fmt.Sprintf("%s (profile %s)", msg, e.Profile)
+	}
+
+	return fmt.Sprintf("%s: %s", msg, strings.Join(e.Missing, ", "))
+}
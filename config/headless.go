@@ -0,0 +1,155 @@
+package config
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"io"
+	"net/url"
+	"strings"
+
+	"github.com/pkg/errors"
+	"golang.org/x/oauth2"
+)
+
+// Parses what the user pasted after authorizing: either the full redirect
+// URL (or just its query string), or the bare authorization code.
+//
+// A redirect URL must carry `state`, and is rejected if its state is missing
+// or different. A bare code carries no state to check, so it is only
+// accepted when `pkce` is set: the code is then bound to this login's PKCE
+// verifier, and a code obtained by anyone else fails the exchange.
+func parseRedirectInput(input string, state string, pkce bool) (string, error) {
+
+	input = strings.TrimSpace(input)
+	if input == "" {
+		return "", errors.New("No redirect URL or authorization code was entered")
+	}
+
+	query, ok := redirectQuery(input)
+	if !ok {
+		if !pkce {
+			return "", errors.New("Paste the full redirect URL: a bare code cannot be checked against the OAuth state")
+		}
+		return input, nil
+	}
+
+	if e := query.Get("error"); e != "" {
+		return "", providerError(e, query.Get("error_description"))
+	}
+
+	switch query.Get("state") {
+	case "":
+		return "", &CallbackError{Reason: ErrStateExpired}
+	case state:
+	default:
+		return "", &CallbackError{Reason: ErrStateMismatch}
+	}
+
+	code := query.Get("code")
+	if code == "" {
+		return "", &CallbackError{Reason: ErrMissingAuthorization}
+	}
+
+	return code, nil
+}
+
+// Returns the query of a pasted redirect URL or query string. `ok` is false
+// for anything else, which is taken to be a bare code; codes may themselves
+// contain "=".
+func redirectQuery(input string) (query url.Values, ok bool) {
+
+	rawQuery := input
+	if i := strings.Index(input, "?"); i >= 0 {
+		rawQuery = input[i+1:]
+	}
+
+	query, err := url.ParseQuery(rawQuery)
+	if err != nil {
+		return nil, false
+	}
+
+	for _, key := range []string{"code", "state", "error"} {
+		if _, ok := query[key]; ok {
+			return query, true
+		}
+	}
+
+	return nil, false
+}
+
+// Reads one line from `in`, giving up when ctx is done. Reads from a terminal
+// cannot be interrupted, so the read is left running in the background.
+func readLine(ctx context.Context, in io.Reader) (string, error) {
+
+	if in == nil {
+		return "", errors.New("No input to read the redirect URL from")
+	}
+
+	result := make(chan loginResult, 1)
+	lines := make(chan string, 1)
+	go func() {
+		line, err := bufio.NewReader(in).ReadString('\n')
+		if err != nil && !(err == io.EOF && line != "") {
+			result <- loginResult{err: errors.Wrap(err, "Unable to read redirect URL")}
+			return
+		}
+		lines <- line
+	}()
+
+	select {
+	case line := <-lines:
+		return line, nil
+	case res := <-result:
+		return "", res.err
+	case <-ctx.Done():
+		return "", ctx.Err()
+	}
+}
+
+// Prints the authorization URL to `out`, reads the pasted redirect URL or
+// code from `in`, verifies the state and exchanges the code (with its PKCE
+// verifier) for a token.
+func (c *config) headlessLogin(ctx context.Context, in io.Reader, out io.Writer) (*oauth2.Token, error) {
+
+	state, err := generateRandomString(16)
+	if err != nil {
+		return nil, err
+	}
+
+	verifier, err := generatePKCEVerifier()
+	if err != nil {
+		return nil, err
+	}
+
+	authCodeURL := c.OAuth2Conf.AuthCodeURL(state, pkceChallengeOptions(verifier)...)
+	fmt.Fprintf(out, "Open the following URL in a browser and authorize go-inoreader:\n\n%s\n\n", authCodeURL)
+	fmt.Fprint(out, "Paste the URL you were redirected to (or the code): ")
+
+	line, err := readLine(ctx, in)
+	if err != nil {
+		return nil, err
+	}
+
+	code, err := parseRedirectInput(line, state, true)
+	if err != nil {
+		return nil, err
+	}
+
+	token, err := c.OAuth2Conf.Exchange(ctx, code, pkceVerifierOption(verifier))
+	if err != nil {
+		return nil, &CallbackError{Reason: ErrExchangeFailed, Err: err}
+	}
+
+	return token, nil
+}
+
+// Initiates OAuth flow without a local browser. The authorization URL is
+// printed to `out`; after authorizing on any device, the user pastes the
+// redirect URL or the code into `in`. The token is written to
+// go-inoreader.json.
+func InitHeadless(ctx context.Context, in io.Reader, out io.Writer) error {
+
+	_, err := Login(ctx, &LoginOptions{Headless: true, In: in, Out: out})
+	return err
+}
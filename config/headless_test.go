@@ -0,0 +1,108 @@
+package config
+
+import (
+	"bytes"
+	"context"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/pkg/errors"
+	"golang.org/x/oauth2"
+)
+
+func TestParseRedirectInput(t *testing.T) {
+	tests := []struct {
+		input   string
+		pkce    bool
+		code    string
+		wantErr bool
+	}{
+		{"http://localhost:8081/oauth/redirect?code=abc&state=xyz\n", false, "abc", false},
+		{"?code=abc&state=xyz", false, "abc", false},
+		{"code=abc&state=xyz", false, "abc", false},
+		{"abc\n", true, "abc", false},
+		{"abc==", true, "abc==", false},
+		{"abc", false, "", true},
+		{"http://localhost:8081/oauth/redirect?code=abc&state=other", true, "", true},
+		{"http://localhost:8081/oauth/redirect?code=abc", true, "", true},
+		{"code=abc&state=", true, "", true},
+		{"http://localhost:8081/oauth/redirect?error=access_denied&state=xyz", true, "", true},
+		{"http://localhost:8081/oauth/redirect?state=xyz", true, "", true},
+		{"\n", true, "", true},
+	}
+
+	for _, tt := range tests {
+		code, err := parseRedirectInput(tt.input, "xyz", tt.pkce)
+		if (err != nil) != tt.wantErr {
+			t.Fatalf("parseRedirectInput(%q, %v) error = %v, wantErr %v", tt.input, tt.pkce, err, tt.wantErr)
+		}
+
+		if code != tt.code {
+			t.Fatalf("parseRedirectInput(%q) = %q, want %q", tt.input, code, tt.code)
+		}
+	}
+}
+
+func TestHeadlessLogin(t *testing.T) {
+	var gotVerifier string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotVerifier = r.FormValue("code_verifier")
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"access_token": "access", "refresh_token": "refresh", "token_type": "Bearer", "expires_in": 3600}`))
+	}))
+	defer srv.Close()
+
+	cfg := &config{
+		Credentials: Credentials{AppID: "1000000000", AppKey: "secret"},
+		OAuth2Conf: &oauth2.Config{
+			ClientID:     "1000000000",
+			ClientSecret: "secret",
+			RedirectURL:  redirectURL,
+			Endpoint:     oauth2.Endpoint{AuthURL: authURL, TokenURL: srv.URL},
+		},
+	}
+
+	var out bytes.Buffer
+	token, err := cfg.headlessLogin(context.Background(), strings.NewReader("pasted-code\n"), &out)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if token.AccessToken != "access" {
+		t.Fatalf("AccessToken %q, want access", token.AccessToken)
+	}
+
+	if gotVerifier == "" {
+		t.Fatal("token request carried no PKCE code_verifier")
+	}
+
+	if !strings.Contains(out.String(), "code_challenge_method=S256") {
+		t.Fatalf("authorization URL has no PKCE challenge:\n%s", out.String())
+	}
+}
+
+func TestHeadlessLoginState(t *testing.T) {
+	cfg := &config{
+		Credentials: Credentials{AppID: "1000000000", AppKey: "secret"},
+		OAuth2Conf: &oauth2.Config{
+			ClientID:    "1000000000",
+			RedirectURL: redirectURL,
+			Endpoint:    oauth2.Endpoint{AuthURL: authURL, TokenURL: "http://127.0.0.1:0/token"},
+		},
+	}
+
+	inputs := map[string]error{
+		"http://localhost:8081/oauth/redirect?code=abc&state=forged\n": ErrStateMismatch,
+		"http://localhost:8081/oauth/redirect?code=abc\n":              ErrStateExpired,
+	}
+
+	for input, want := range inputs {
+		_, err := cfg.headlessLogin(context.Background(), strings.NewReader(input), ioutil.Discard)
+		if !errors.Is(err, want) {
+			t.Fatalf("headlessLogin(%q) error = %v, want %v", input, err, want)
+		}
+	}
+}
@@ -0,0 +1,220 @@
+package config
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"net/url"
+	"strconv"
+	"time"
+
+	"github.com/pkg/errors"
+	"golang.org/x/oauth2"
+)
+
+const (
+	defaultLoginTimeout  = 5 * time.Minute
+	loginShutdownTimeout = 5 * time.Second
+)
+
+// ErrLoginTimeout is returned by Login when no callback arrives within
+// LoginOptions.Timeout.
+var ErrLoginTimeout = errors.New("Timed out waiting for the OAuth callback")
+
+// LoginOptions configures Login. The zero value listens on port 8081 and
+// redirects to http://localhost:8081/oauth/redirect.
+type LoginOptions struct {
+	// Port the login server listens on. It binds to the loopback interface
+	// only. Defaults to the port of RedirectURL, or 8081.
+	Port int
+
+	// RedirectURL registered for the app under Preferences > Developer. Its
+	// path is where the callback is served. Defaults to
+	// http://localhost:<Port>/oauth/redirect.
+	RedirectURL string
+
+	// Timeout for the whole login. Defaults to 5 minutes.
+	Timeout time.Duration
+
+	// Headless prints the authorization URL to Out and reads the redirect
+	// URL or code from In instead of running a login server.
+	Headless bool
+
+	// In is read in headless mode.
+	In io.Reader
+
+	// Out receives the URL to open in a browser. Defaults to discarding it.
+	Out io.Writer
+
+	// Store the app credentials are loaded from and the token is saved to.
+	// Defaults to the go-inoreader.json FileStore.
+	Store TokenStore
+}
+
+// Fills in the defaults of *LoginOptions and returns the resulting copy.
+func (o *LoginOptions) withDefaults() (LoginOptions, error) {
+
+	var opts LoginOptions
+	if o != nil {
+		opts = *o
+	}
+
+	if opts.Port == 0 && opts.RedirectURL != "" {
+		u, err := url.Parse(opts.RedirectURL)
+		if err != nil {
+			return opts, errors.Wrapf(err, "Invalid redirect URL: %s", opts.RedirectURL)
+		}
+
+		if p := u.Port(); p != "" {
+			opts.Port, _ = strconv.Atoi(p)
+		}
+	}
+
+	if opts.Port == 0 {
+		opts.Port = defaultLoginPort
+	}
+
+	if opts.RedirectURL == "" {
+		opts.RedirectURL = fmt.Sprintf("http://localhost:%d/oauth/redirect", opts.Port)
+	}
+
+	if opts.Timeout == 0 {
+		opts.Timeout = defaultLoginTimeout
+	}
+
+	if opts.Out == nil {
+		opts.Out = ioutil.Discard
+	}
+
+	if opts.Store == nil {
+		opts.Store = NewFileStore("")
+	}
+
+	return opts, nil
+}
+
+type loginResult struct {
+	token *oauth2.Token
+	err   error
+}
+
+// loginServer serves the OAuth login and callback pages on its own
+// ServeMux and hands the first callback result to Login.
+type loginServer struct {
+	ctx    context.Context
+	cfg    *config
+	result chan loginResult
+}
+
+// Hands the callback result to Login. Only the first result is kept.
+func (s *loginServer) finish(token *oauth2.Token, err error) {
+
+	select {
+	case s.result <- loginResult{token: token, err: err}:
+	default:
+	}
+}
+
+// Runs the OAuth flow and returns the token once the user has authorized
+// go-inoreader. The token is also saved to opts.Store, go-inoreader.json by
+// default.
+//
+// Unless opts.Headless is set, a login server is started on the loopback
+// interface; the user opens its URL (printed to opts.Out) in a browser. The
+// server is shut down before Login returns. Login gives up with
+// ErrLoginTimeout after opts.Timeout, or with ctx.Err() when ctx is done.
+func Login(ctx context.Context, opts *LoginOptions) (*oauth2.Token, error) {
+
+	o, err := opts.withDefaults()
+	if err != nil {
+		return nil, err
+	}
+
+	creds, err := o.Store.Load()
+	if err != nil {
+		return nil, err
+	}
+
+	c := &config{Credentials: *creds}
+	c.getOauthConf()
+	c.OAuth2Conf.RedirectURL = o.RedirectURL
+
+	loginCtx, cancel := context.WithTimeout(ctx, o.Timeout)
+	defer cancel()
+
+	var token *oauth2.Token
+	if o.Headless {
+		token, err = c.headlessLogin(loginCtx, o.In, o.Out)
+	} else {
+		token, err = c.serveLogin(loginCtx, o)
+	}
+
+	if err != nil {
+		if loginCtx.Err() == context.DeadlineExceeded && ctx.Err() == nil {
+			return nil, ErrLoginTimeout
+		}
+		return nil, err
+	}
+
+	c.SetToken(token)
+	if err := o.Store.Save(&c.Credentials); err != nil {
+		return nil, err
+	}
+
+	return token, nil
+}
+
+// Serves the login and callback pages until a callback arrives or ctx is
+// done, then shuts the server down gracefully.
+func (c *config) serveLogin(ctx context.Context, o LoginOptions) (*oauth2.Token, error) {
+
+	u, err := url.Parse(o.RedirectURL)
+	if err != nil {
+		return nil, errors.Wrapf(err, "Invalid redirect URL: %s", o.RedirectURL)
+	}
+
+	callbackPath := u.Path
+	if callbackPath == "" || callbackPath == "/" {
+		return nil, errors.Errorf("Redirect URL needs a callback path: %s", o.RedirectURL)
+	}
+
+	s := &loginServer{
+		ctx:    ctx,
+		cfg:    c,
+		result: make(chan loginResult, 1),
+	}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/", s.handleInoreaderLogin)
+	mux.HandleFunc(callbackPath, s.handleInoreaderCallback)
+
+	listener, err := net.Listen("tcp", net.JoinHostPort("127.0.0.1", strconv.Itoa(o.Port)))
+	if err != nil {
+		return nil, errors.Wrap(err, "Unable to start login server")
+	}
+
+	srv := &http.Server{Handler: mux}
+	go func() {
+		if err := srv.Serve(listener); err != nil && err != http.ErrServerClosed {
+			s.finish(nil, errors.Wrap(err, "Login server error"))
+		}
+	}()
+
+	defer func() {
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), loginShutdownTimeout)
+		defer cancel()
+		srv.Shutdown(shutdownCtx)
+	}()
+
+	fmt.Fprintf(o.Out, "Open http://localhost:%d in a browser to log in to Inoreader\n", o.Port)
+
+	select {
+	case res := <-s.result:
+		return res.token, res.err
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+}
@@ -0,0 +1,188 @@
+package config
+
+import (
+	"context"
+	"fmt"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"net/http/cookiejar"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/pkg/errors"
+	"golang.org/x/oauth2"
+)
+
+// Returns a config whose token endpoint is `tokenURL`.
+func testLoginConfig(tokenURL string) *config {
+
+	c := &config{Credentials: Credentials{AppID: "1000000000", AppKey: "secret"}}
+	c.getOauthConf()
+	c.OAuth2Conf.Endpoint.TokenURL = tokenURL
+
+	return c
+}
+
+// Returns a free port on the loopback interface.
+func freePort(t *testing.T) int {
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer l.Close()
+
+	return l.Addr().(*net.TCPAddr).Port
+}
+
+// Runs serveLogin and returns a channel with its result.
+func startServeLogin(ctx context.Context, c *config, port int) <-chan loginResult {
+
+	o, _ := (&LoginOptions{Port: port}).withDefaults()
+	c.OAuth2Conf.RedirectURL = o.RedirectURL
+
+	done := make(chan loginResult, 1)
+	go func() {
+		token, err := c.serveLogin(ctx, o)
+		done <- loginResult{token: token, err: err}
+	}()
+
+	return done
+}
+
+// Returns an *http.Client that keeps cookies and does not follow redirects.
+func loginTestClient() *http.Client {
+	jar, _ := cookiejar.New(nil)
+	return &http.Client{
+		Jar: jar,
+		CheckRedirect: func(req *http.Request, via []*http.Request) error {
+			return http.ErrUseLastResponse
+		},
+	}
+}
+
+// Waits until the login server on `port` accepts connections.
+func waitForLoginServer(t *testing.T, port int) {
+	for i := 0; i < 100; i++ {
+		conn, err := net.Dial("tcp", fmt.Sprintf("127.0.0.1:%d", port))
+		if err == nil {
+			conn.Close()
+			return
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	t.Fatal("login server did not start")
+}
+
+func TestServeLogin(t *testing.T) {
+	tokenSrv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"access_token": "access", "refresh_token": "refresh", "token_type": "Bearer", "expires_in": 3600}`))
+	}))
+	defer tokenSrv.Close()
+
+	port := freePort(t)
+	done := startServeLogin(context.Background(), testLoginConfig(tokenSrv.URL), port)
+	waitForLoginServer(t, port)
+
+	hc := loginTestClient()
+	base := fmt.Sprintf("http://127.0.0.1:%d", port)
+
+	resp, err := hc.Get(base + "/")
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+
+	authCodeURL, err := url.Parse(resp.Header.Get("Location"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	state := authCodeURL.Query().Get("state")
+
+	resp, err = hc.Get(base + "/oauth/redirect?code=abc&state=" + url.QueryEscape(state))
+	if err != nil {
+		t.Fatal(err)
+	}
+	body, _ := ioutil.ReadAll(resp.Body)
+	resp.Body.Close()
+
+	res := <-done
+	if res.err != nil {
+		t.Fatal(res.err)
+	}
+
+	if res.token.AccessToken != "access" {
+		t.Fatalf("AccessToken %q, want access", res.token.AccessToken)
+	}
+
+	if resp.StatusCode != http.StatusOK || !strings.Contains(string(body), "You may close this page") {
+		t.Fatalf("callback answered %d with:\n%s", resp.StatusCode, body)
+	}
+}
+
+func TestServeLoginCancel(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+
+	c := &config{Credentials: Credentials{AppID: "1000000000", AppKey: "secret"}, OAuth2Conf: &oauth2.Config{}}
+	res := <-startServeLogin(ctx, c, freePort(t))
+	if res.err != context.DeadlineExceeded {
+		t.Fatalf("serveLogin error = %v, want %v", res.err, context.DeadlineExceeded)
+	}
+}
+
+func TestHandleInoreaderCallbackErrors(t *testing.T) {
+	tokenSrv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusBadRequest)
+		w.Write([]byte(`{"error": "invalid_grant"}`))
+	}))
+	defer tokenSrv.Close()
+
+	tests := []struct {
+		name   string
+		query  string
+		cookie string
+		want   error
+	}{
+		{"denied", "?error=access_denied&error_description=User+denied", "xyz", ErrAccessDenied},
+		{"no cookie", "?code=abc&state=xyz", "", ErrStateExpired},
+		{"mismatch", "?code=abc&state=other", "xyz", ErrStateMismatch},
+		{"no code", "?state=xyz", "xyz", ErrMissingAuthorization},
+		{"exchange", "?code=abc&state=xyz", "xyz", ErrExchangeFailed},
+	}
+
+	for _, tt := range tests {
+		s := &loginServer{
+			ctx:    context.Background(),
+			cfg:    testLoginConfig(tokenSrv.URL),
+			result: make(chan loginResult, 1),
+		}
+
+		r := httptest.NewRequest("GET", "/oauth/redirect"+tt.query, nil)
+		if tt.cookie != "" {
+			r.AddCookie(&http.Cookie{Name: "oauthstate", Value: tt.cookie})
+		}
+		w := httptest.NewRecorder()
+
+		s.handleInoreaderCallback(w, r)
+
+		res := <-s.result
+		if !errors.Is(res.err, tt.want) {
+			t.Fatalf("%s: error = %v, want %v", tt.name, res.err, tt.want)
+		}
+
+		var cbErr *CallbackError
+		if !errors.As(res.err, &cbErr) {
+			t.Fatalf("%s: error %T is not a *CallbackError", tt.name, res.err)
+		}
+
+		if w.Code != http.StatusBadRequest {
+			t.Fatalf("%s: status %d, want %d", tt.name, w.Code, http.StatusBadRequest)
+		}
+	}
+}
@@ -0,0 +1,189 @@
+package config
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"runtime"
+	"sync"
+
+	"github.com/pkg/errors"
+)
+
+// EnvConfigPath names the environment variable that points at the config
+// file to use.
+const EnvConfigPath = "INOREADER_CONFIG"
+
+const cfgFileName = "go-inoreader.json"
+
+// PathSource tells which step of the resolution chain chose the config file
+// path.
+type PathSource int
+
+// Sources of the config file path, in resolution order.
+const (
+	// PathExplicit is a path set with SetPath.
+	PathExplicit PathSource = iota
+
+	// PathEnv is the path in $INOREADER_CONFIG.
+	PathEnv
+
+	// PathXDGConfig is $XDG_CONFIG_HOME/go-inoreader.json (~/.config by
+	// default). New config files are created here.
+	PathXDGConfig
+
+	// PathXDGData is $XDG_DATA_HOME/go-inoreader.json, used when
+	// $XDG_DATA_HOME is set.
+	PathXDGData
+
+	// PathLegacy is ~/.local/share/go-inoreader.json on Unix/Linux and
+	// %APPDATA%\go-inoreader.json on Windows.
+	PathLegacy
+)
+
+func (s PathSource) String() string {
+
+	switch s {
+	case PathExplicit:
+		return "explicit"
+	case PathEnv:
+		return EnvConfigPath
+	case PathXDGConfig:
+		return "XDG_CONFIG_HOME"
+	case PathXDGData:
+		return "XDG_DATA_HOME"
+	case PathLegacy:
+		return "legacy"
+	default:
+		return "unknown"
+	}
+}
+
+// MarshalText encodes the source as its String form.
+func (s PathSource) MarshalText() ([]byte, error) {
+	return []byte(s.String()), nil
+}
+
+var (
+	explicitPathMu sync.Mutex
+	explicitPath   string
+)
+
+// SetPath makes every function in this package that uses the default config
+// file use `filePath` instead. An empty path restores the resolution chain.
+func SetPath(filePath string) {
+
+	explicitPathMu.Lock()
+	defer explicitPathMu.Unlock()
+
+	explicitPath = filePath
+}
+
+// Returns the XDG config location of the config file.
+func xdgConfigCfgFilePath() string {
+
+	dir := os.Getenv("XDG_CONFIG_HOME")
+	if dir == "" {
+		homeDir, _ := os.UserHomeDir()
+		dir = filepath.Join(homeDir, ".config")
+	}
+
+	return filepath.Join(dir, cfgFileName)
+}
+
+// Reports whether a file exists at `filePath`.
+func fileExists(filePath string) bool {
+
+	_, err := os.Stat(filePath)
+	return err == nil
+}
+
+// Path returns the path of the config file and the source it came from.
+// The first of these wins:
+//
+//  1. a path set with SetPath
+//  2. $INOREADER_CONFIG
+//  3. $XDG_CONFIG_HOME/go-inoreader.json, if it exists
+//  4. $XDG_DATA_HOME/go-inoreader.json, if set and the file exists
+//  5. the legacy path, if it exists
+//
+// When none of the files exist, the XDG config location is returned so that
+// a new file is created there. Windows has no XDG locations and always uses
+// the legacy %APPDATA% path.
+func Path() (string, PathSource) {
+
+	explicitPathMu.Lock()
+	filePath := explicitPath
+	explicitPathMu.Unlock()
+
+	if filePath != "" {
+		return filePath, PathExplicit
+	}
+
+	if filePath := os.Getenv(EnvConfigPath); filePath != "" {
+		return filePath, PathEnv
+	}
+
+	if runtime.GOOS == "windows" {
+		return legacyCfgFilePath(), PathLegacy
+	}
+
+	xdgConfig := xdgConfigCfgFilePath()
+	if fileExists(xdgConfig) {
+		return xdgConfig, PathXDGConfig
+	}
+
+	if dir := os.Getenv("XDG_DATA_HOME"); dir != "" {
+		if filePath := filepath.Join(dir, cfgFileName); fileExists(filePath) {
+			return filePath, PathXDGData
+		}
+	}
+
+	if legacy := legacyCfgFilePath(); fileExists(legacy) {
+		return legacy, PathLegacy
+	}
+
+	return xdgConfig, PathXDGConfig
+}
+
+// MigrateLegacyConfig moves the config file from the legacy path to the XDG
+// config location and returns both paths. It does nothing when there is no
+// legacy file, and fails rather than overwrite an existing file at the new
+// location.
+func MigrateLegacyConfig() (from string, to string, err error) {
+
+	from = legacyCfgFilePath()
+	to = xdgConfigCfgFilePath()
+
+	if runtime.GOOS == "windows" || from == to || !fileExists(from) {
+		return from, to, nil
+	}
+
+	if fileExists(to) {
+		return from, to, errors.Errorf("Config file already exists: %s", to)
+	}
+
+	if err := os.MkdirAll(filepath.Dir(to), 0700); err != nil {
+		return from, to, errors.Wrapf(err, "Unable to create config directory: %s", filepath.Dir(to))
+	}
+
+	if err := os.Rename(from, to); err == nil {
+		return from, to, nil
+	}
+
+	// Rename fails across file systems; copy and remove instead.
+	data, err := ioutil.ReadFile(from)
+	if err != nil {
+		return from, to, errors.Wrapf(err, "An error occurred while trying to read config file: %s", from)
+	}
+
+	if err := writeFileAtomic(to, data, 0600); err != nil {
+		return from, to, errors.Wrapf(err, "Unable to write config file: %s", to)
+	}
+
+	if err := os.Remove(from); err != nil {
+		return from, to, errors.Wrapf(err, "Unable to remove config file: %s", from)
+	}
+
+	return from, to, nil
+}
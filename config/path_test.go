@@ -0,0 +1,58 @@
+package config
+
+import (
+	"io/ioutil"
+	"path/filepath"
+	"runtime"
+	"testing"
+)
+
+func TestPath(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("no XDG locations on Windows")
+	}
+
+	home := t.TempDir()
+	setenv(t, "HOME", home)
+	setenv(t, "XDG_CONFIG_HOME", filepath.Join(home, "xdg-config"))
+	setenv(t, "XDG_DATA_HOME", filepath.Join(home, "xdg-data"))
+	setenv(t, EnvConfigPath, "")
+
+	xdgConfig := filepath.Join(home, "xdg-config", "go-inoreader.json")
+	if filePath, source := Path(); filePath != xdgConfig || source != PathXDGConfig {
+		t.Fatalf("Path() = %q, %v; want %q, %v", filePath, source, xdgConfig, PathXDGConfig)
+	}
+
+	legacy := filepath.Join(home, ".local", "share", "go-inoreader.json")
+	if err := writeFileAtomic(legacy, []byte(`{"app_id": "1", "app_key": "a"}`), 0600); err != nil {
+		t.Fatal(err)
+	}
+	if filePath, source := Path(); filePath != legacy || source != PathLegacy {
+		t.Fatalf("Path() = %q, %v; want %q, %v", filePath, source, legacy, PathLegacy)
+	}
+
+	from, to, err := MigrateLegacyConfig()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if from != legacy || to != xdgConfig || fileExists(legacy) {
+		t.Fatalf("MigrateLegacyConfig() moved %q to %q, legacy file left: %v", from, to, fileExists(legacy))
+	}
+	if data, _ := ioutil.ReadFile(xdgConfig); len(data) == 0 {
+		t.Fatal("migrated config file is empty")
+	}
+	if filePath, source := Path(); filePath != xdgConfig || source != PathXDGConfig {
+		t.Fatalf("Path() = %q, %v; want %q, %v", filePath, source, xdgConfig, PathXDGConfig)
+	}
+
+	setenv(t, EnvConfigPath, "/tmp/env.json")
+	if filePath, source := Path(); filePath != "/tmp/env.json" || source != PathEnv {
+		t.Fatalf("Path() = %q, %v; want /tmp/env.json, %v", filePath, source, PathEnv)
+	}
+
+	SetPath("/tmp/explicit.json")
+	defer SetPath("")
+	if filePath, source := Path(); filePath != "/tmp/explicit.json" || source != PathExplicit {
+		t.Fatalf("Path() = %q, %v; want /tmp/explicit.json, %v", filePath, source, PathExplicit)
+	}
+}
@@ -0,0 +1,31 @@
+package config
+
+import (
+	"crypto/sha256"
+	"encoding/base64"
+
+	"golang.org/x/oauth2"
+)
+
+// Generates a PKCE code verifier as described in RFC 7636, section 4.1.
+func generatePKCEVerifier() (string, error) {
+	return generateRandomString(32)
+}
+
+// Returns the AuthCodeURL options that send the S256 code challenge derived
+// from `verifier`.
+func pkceChallengeOptions(verifier string) []oauth2.AuthCodeOption {
+
+	sum := sha256.Sum256([]byte(verifier))
+	challenge := base64.RawURLEncoding.EncodeToString(sum[:])
+
+	return []oauth2.AuthCodeOption{
+		oauth2.SetAuthURLParam("code_challenge", challenge),
+		oauth2.SetAuthURLParam("code_challenge_method", "S256"),
+	}
+}
+
+// Returns the Exchange option that sends the PKCE code verifier.
+func pkceVerifierOption(verifier string) oauth2.AuthCodeOption {
+	return oauth2.SetAuthURLParam("code_verifier", verifier)
+}
@@ -0,0 +1,229 @@
+package config
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"sort"
+	"sync"
+
+	"github.com/pkg/errors"
+)
+
+// DefaultProfile is the name given to the credentials of a config file
+// written before profiles existed.
+const DefaultProfile = "default"
+
+// ErrNoSuchProfile is returned when a named profile is not in the config
+// file.
+var ErrNoSuchProfile = errors.New("No such profile")
+
+// Serializes read-modify-write cycles on config files, so tokens refreshed
+// for different profiles at the same time do not overwrite each other.
+var cfgFileMu sync.Mutex
+
+// On-disk layout of go-inoreader.json with named profiles. A file without
+// "profiles" is the legacy flat layout; it is read as the DefaultProfile and
+// migrated to this layout the next time the file is written.
+type cfgFile struct {
+	CurrentProfile string                  `json:"current_profile"`
+	Profiles       map[string]*Credentials `json:"profiles"`
+}
+
+// Reads the config file at `filePath`, converting the legacy flat layout.
+func readCfgFile(filePath string) (*cfgFile, error) {
+
+	// Check if file exists
+	if _, err := os.Stat(filePath); os.IsNotExist(err) {
+		return nil, errors.Wrapf(err, "Config file does not exist: %s", filePath)
+	}
+
+	data, err := ioutil.ReadFile(filePath)
+	if err != nil {
+		return nil, errors.Wrapf(err, "An error occurred while trying to read config file: %s", filePath)
+	}
+
+	var f cfgFile
+	if err := json.Unmarshal(data, &f); err != nil {
+		return nil, errors.Wrapf(err, "Unable to unmarshal JSON content: %s", filePath)
+	}
+
+	if f.Profiles == nil {
+		var creds Credentials
+		if err := json.Unmarshal(data, &creds); err != nil {
+			return nil, errors.Wrapf(err, "Unable to unmarshal JSON content: %s", filePath)
+		}
+
+		f.CurrentProfile = DefaultProfile
+		f.Profiles = map[string]*Credentials{DefaultProfile: &creds}
+	}
+
+	return &f, nil
+}
+
+// Reads the config file at `filePath`, or returns an empty one if it does
+// not exist yet.
+func readOrNewCfgFile(filePath string) (*cfgFile, error) {
+
+	f, err := readCfgFile(filePath)
+	if errors.Is(err, os.ErrNotExist) {
+		return &cfgFile{Profiles: map[string]*Credentials{}}, nil
+	}
+
+	return f, err
+}
+
+// Writes the config file to `filePath` in the profile layout.
+func (f *cfgFile) write(filePath string) error {
+
+	jsonData, err := json.MarshalIndent(f, "", "  ")
+	if err != nil {
+		return errors.Wrap(err, "Unable to parse JSON data")
+	}
+
+	if err := writeFileAtomic(filePath, jsonData, 0600); err != nil {
+		return errors.Wrapf(err, "Unable to write JSON data to config file: %v", filePath)
+	}
+
+	return nil
+}
+
+// Returns the name `profile` refers to: itself, or the current profile when
+// empty.
+func (f *cfgFile) resolve(profile string) string {
+
+	if profile != "" {
+		return profile
+	}
+
+	if f.CurrentProfile != "" {
+		return f.CurrentProfile
+	}
+
+	return DefaultProfile
+}
+
+// Returns the names of the profiles in the config file, sorted.
+func (f *cfgFile) names() []string {
+
+	names := make([]string, 0, len(f.Profiles))
+	for name := range f.Profiles {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	return names
+}
+
+// Removes the profile `name`. If it was the current profile, the first
+// remaining profile by name becomes current.
+func (f *cfgFile) remove(name string) {
+
+	delete(f.Profiles, name)
+
+	if f.CurrentProfile == name {
+		f.CurrentProfile = ""
+		if names := f.names(); len(names) > 0 {
+			f.CurrentProfile = names[0]
+		}
+	}
+}
+
+// Reads the config file, lets `fn` change it and writes it back, holding
+// cfgFileMu throughout.
+func updateCfgFile(filePath string, fn func(f *cfgFile) error) error {
+
+	cfgFileMu.Lock()
+	defer cfgFileMu.Unlock()
+
+	f, err := readOrNewCfgFile(filePath)
+	if err != nil {
+		return err
+	}
+
+	if err := fn(f); err != nil {
+		return err
+	}
+
+	return f.write(filePath)
+}
+
+// Profiles returns the names of the profiles in the config file and the
+// name of the current one.
+func (s *FileStore) Profiles() (names []string, current string, err error) {
+
+	f, err := readCfgFile(s.Path)
+	if err != nil {
+		return nil, "", err
+	}
+
+	return f.names(), f.resolve(""), nil
+}
+
+// AddProfile adds or replaces the profile `name`. The first profile added
+// to a file becomes the current one.
+func (s *FileStore) AddProfile(name string, creds *Credentials) error {
+
+	if name == "" {
+		return errors.New("Profile name must not be empty")
+	}
+
+	return updateCfgFile(s.Path, func(f *cfgFile) error {
+		c := *creds
+		f.Profiles[name] = &c
+
+		if f.CurrentProfile == "" {
+			f.CurrentProfile = name
+		}
+
+		return nil
+	})
+}
+
+// RemoveProfile removes the profile `name`. If it was the current profile,
+// the first remaining profile by name becomes current.
+func (s *FileStore) RemoveProfile(name string) error {
+
+	return updateCfgFile(s.Path, func(f *cfgFile) error {
+		if _, ok := f.Profiles[name]; !ok {
+			return errors.Wrap(ErrNoSuchProfile, name)
+		}
+		f.remove(name)
+
+		return nil
+	})
+}
+
+// SelectProfile makes `name` the current profile.
+func (s *FileStore) SelectProfile(name string) error {
+
+	return updateCfgFile(s.Path, func(f *cfgFile) error {
+		if _, ok := f.Profiles[name]; !ok {
+			return errors.Wrap(ErrNoSuchProfile, name)
+		}
+		f.CurrentProfile = name
+
+		return nil
+	})
+}
+
+// ListProfiles returns the profiles in go-inoreader.json and the name of the
+// current one.
+func ListProfiles() (names []string, current string, err error) {
+	return NewFileStore("").Profiles()
+}
+
+// AddProfile adds or replaces the profile `name` in go-inoreader.json.
+func AddProfile(name string, creds *Credentials) error {
+	return NewFileStore("").AddProfile(name, creds)
+}
+
+// RemoveProfile removes the profile `name` from go-inoreader.json.
+func RemoveProfile(name string) error {
+	return NewFileStore("").RemoveProfile(name)
+}
+
+// SelectProfile makes `name` the current profile in go-inoreader.json.
+func SelectProfile(name string) error {
+	return NewFileStore("").SelectProfile(name)
+}
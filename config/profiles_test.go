@@ -0,0 +1,93 @@
+package config
+
+import (
+	"io/ioutil"
+	"path/filepath"
+	"testing"
+
+	"github.com/pkg/errors"
+)
+
+func TestLegacyConfigMigration(t *testing.T) {
+	filePath := filepath.Join(t.TempDir(), "go-inoreader.json")
+	legacy := `{"app_id": "1000000000", "app_key": "secret", "access_token": "access", "refresh_token": "refresh"}`
+	if err := ioutil.WriteFile(filePath, []byte(legacy), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	store := NewFileStore(filePath)
+	creds, err := store.Load()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if creds.AppID != "1000000000" || store.Profile != DefaultProfile {
+		t.Fatalf("loaded %#v from profile %q, want app 1000000000 from %q", creds, store.Profile, DefaultProfile)
+	}
+
+	research := &Credentials{AppID: "2000000000", AppKey: "research-secret"}
+	if err := store.AddProfile("research", research); err != nil {
+		t.Fatal(err)
+	}
+
+	names, current, err := store.Profiles()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(names) != 2 || names[0] != DefaultProfile || names[1] != "research" || current != DefaultProfile {
+		t.Fatalf("profiles %v (current %q), want [default research] (current default)", names, current)
+	}
+}
+
+func TestProfileTokensAreSeparate(t *testing.T) {
+	filePath := filepath.Join(t.TempDir(), "go-inoreader.json")
+	store := NewFileStore(filePath)
+
+	if err := store.AddProfile("personal", &Credentials{AppID: "1", AppKey: "a", AccessToken: "personal-old"}); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := store.AddProfile("research", &Credentials{AppID: "2", AppKey: "b", AccessToken: "research-old"}); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := store.SelectProfile("research"); err != nil {
+		t.Fatal(err)
+	}
+
+	personal := NewProfileStore(filePath, "personal")
+	creds, err := personal.Load()
+	if err != nil {
+		t.Fatal(err)
+	}
+	creds.AccessToken = "personal-new"
+	if err := personal.Save(creds); err != nil {
+		t.Fatal(err)
+	}
+
+	research, err := NewFileStore(filePath).Load()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if research.AccessToken != "research-old" {
+		t.Fatalf("research AccessToken %q, want research-old", research.AccessToken)
+	}
+
+	if creds, _ := personal.Load(); creds.AccessToken != "personal-new" {
+		t.Fatalf("personal AccessToken %q, want personal-new", creds.AccessToken)
+	}
+
+	if err := store.RemoveProfile("research"); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, current, _ := store.Profiles(); current != "personal" {
+		t.Fatalf("current profile %q after removing research, want personal", current)
+	}
+
+	if err := store.SelectProfile("research"); !errors.Is(err, ErrNoSuchProfile) {
+		t.Fatalf("SelectProfile error = %v, want %v", err, ErrNoSuchProfile)
+	}
+}
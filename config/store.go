@@ -0,0 +1,213 @@
+package config
+
+import (
+	"os"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// Errors returned by TokenStore implementations.
+var (
+	ErrNoCredentials = errors.New("No credentials stored")
+	ErrReadOnlyStore = errors.New("Token store is read-only")
+)
+
+// TokenStore loads and saves the app credentials and OAuth token. Tokens
+// refreshed by the clients in this package are written back through Save;
+// stores that cannot be written return ErrReadOnlyStore and keep serving
+// the credentials they were loaded with.
+type TokenStore interface {
+	Load() (*Credentials, error)
+	Save(creds *Credentials) error
+	Delete() error
+}
+
+// FileStore keeps credentials in a profile of a go-inoreader.json file. An
+// empty Profile means the file's current profile; Load pins the store to the
+// profile it read, so tokens refreshed later are saved to that same profile.
+type FileStore struct {
+	Path    string
+	Profile string
+}
+
+// NewFileStore returns a FileStore for the current profile of the config
+// file at `filePath`. An empty path means the default go-inoreader.json
+// location.
+func NewFileStore(filePath string) *FileStore {
+	return NewProfileStore(filePath, "")
+}
+
+// NewProfileStore returns a FileStore for the profile `profile` of the
+// config file at `filePath`. An empty path means the default
+// go-inoreader.json location.
+func NewProfileStore(filePath string, profile string) *FileStore {
+
+	if filePath == "" {
+		filePath = getCfgFilePath()
+	}
+
+	return &FileStore{Path: filePath, Profile: profile}
+}
+
+// Load reads the credentials of the profile from the config file.
+func (s *FileStore) Load() (*Credentials, error) {
+
+	cfg, err := loadProfile(s.Path, s.Profile)
+	if err != nil {
+		return nil, err
+	}
+	s.Profile = cfg.profile
+
+	return &cfg.Credentials, nil
+}
+
+// Save writes `creds` to the profile in the config file through
+// writeCfgFile.
+func (s *FileStore) Save(creds *Credentials) error {
+
+	cfg := &config{Credentials: *creds, profile: s.Profile}
+	return cfg.writeCfgFile(s.Path, creds.Token())
+}
+
+// Delete removes the profile from the config file, and the file itself once
+// no profiles are left.
+func (s *FileStore) Delete() error {
+
+	cfgFileMu.Lock()
+	defer cfgFileMu.Unlock()
+
+	f, err := readCfgFile(s.Path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	f.remove(f.resolve(s.Profile))
+
+	if len(f.Profiles) > 0 {
+		return f.write(s.Path)
+	}
+
+	if err := os.Remove(s.Path); err != nil && !os.IsNotExist(err) {
+		return errors.Wrapf(err, "Unable to remove config file: %s", s.Path)
+	}
+
+	return nil
+}
+
+// Environment variables read by EnvStore.
+const (
+	EnvAppID        = "INOREADER_APP_ID"
+	EnvAppKey       = "INOREADER_APP_KEY"
+	EnvAccessToken  = "INOREADER_ACCESS_TOKEN"
+	EnvRefreshToken = "INOREADER_REFRESH_TOKEN"
+	EnvTokenType    = "INOREADER_TOKEN_TYPE"
+	EnvTokenExpiry  = "INOREADER_TOKEN_EXPIRY"
+)
+
+// EnvStore reads credentials from the INOREADER_* environment variables,
+// for containers where no config file is mounted. INOREADER_TOKEN_EXPIRY is
+// an RFC 3339 timestamp. EnvStore is read-only: refreshed tokens are kept in
+// memory only.
+type EnvStore struct{}
+
+// NewEnvStore returns an EnvStore.
+func NewEnvStore() *EnvStore {
+	return &EnvStore{}
+}
+
+// Load reads the credentials from the environment.
+func (s *EnvStore) Load() (*Credentials, error) {
+
+	creds := &Credentials{
+		AppID:        os.Getenv(EnvAppID),
+		AppKey:       os.Getenv(EnvAppKey),
+		AccessToken:  os.Getenv(EnvAccessToken),
+		RefreshToken: os.Getenv(EnvRefreshToken),
+		TokenType:    os.Getenv(EnvTokenType),
+	}
+
+	if expiry := os.Getenv(EnvTokenExpiry); expiry != "" {
+		t, err := time.Parse(time.RFC3339, expiry)
+		if err != nil {
+			return nil, errors.Wrapf(err, "Invalid %s: %s", EnvTokenExpiry, expiry)
+		}
+		creds.Expiry = t
+	}
+
+	if *creds == (Credentials{}) {
+		return nil, ErrNoCredentials
+	}
+
+	return creds, nil
+}
+
+// Save returns ErrReadOnlyStore.
+func (s *EnvStore) Save(creds *Credentials) error {
+	return ErrReadOnlyStore
+}
+
+// Delete returns ErrReadOnlyStore.
+func (s *EnvStore) Delete() error {
+	return ErrReadOnlyStore
+}
+
+// MemoryStore keeps credentials in memory, mostly for tests.
+type MemoryStore struct {
+	mu    sync.Mutex
+	creds *Credentials
+}
+
+// NewMemoryStore returns a MemoryStore holding a copy of `creds`, which may
+// be nil.
+func NewMemoryStore(creds *Credentials) *MemoryStore {
+
+	s := &MemoryStore{}
+	if creds != nil {
+		c := *creds
+		s.creds = &c
+	}
+
+	return s
+}
+
+// Load returns a copy of the stored credentials.
+func (s *MemoryStore) Load() (*Credentials, error) {
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.creds == nil {
+		return nil, ErrNoCredentials
+	}
+
+	c := *s.creds
+	return &c, nil
+}
+
+// Save stores a copy of `creds`.
+func (s *MemoryStore) Save(creds *Credentials) error {
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	c := *creds
+	s.creds = &c
+
+	return nil
+}
+
+// Delete forgets the stored credentials.
+func (s *MemoryStore) Delete() error {
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.creds = nil
+
+	return nil
+}
@@ -0,0 +1,98 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+var testCreds = &Credentials{
+	AppID:        "1000000000",
+	AppKey:       "secret",
+	AccessToken:  "access",
+	RefreshToken: "refresh",
+	TokenType:    "Bearer",
+	Expiry:       time.Date(2030, 1, 2, 3, 4, 5, 0, time.UTC),
+}
+
+// Saves testCreds to `store`, loads them back and deletes them.
+func testStoreRoundTrip(t *testing.T, store TokenStore) {
+	if err := store.Save(testCreds); err != nil {
+		t.Fatal(err)
+	}
+
+	creds, err := store.Load()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !creds.Expiry.Equal(testCreds.Expiry) {
+		t.Fatalf("Expiry %v, want %v", creds.Expiry, testCreds.Expiry)
+	}
+	creds.Expiry = testCreds.Expiry
+
+	if *creds != *testCreds {
+		t.Fatalf("loaded %#v, want %#v", creds, testCreds)
+	}
+
+	if err := store.Delete(); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := store.Load(); err == nil {
+		t.Fatal("Load after Delete returned no error")
+	}
+}
+
+func TestFileStore(t *testing.T) {
+	testStoreRoundTrip(t, NewFileStore(filepath.Join(t.TempDir(), "go-inoreader.json")))
+}
+
+func TestMemoryStore(t *testing.T) {
+	testStoreRoundTrip(t, NewMemoryStore(nil))
+}
+
+func TestEncryptedFileStore(t *testing.T) {
+	filePath := filepath.Join(t.TempDir(), "go-inoreader.enc")
+	testStoreRoundTrip(t, NewEncryptedFileStore(filePath, "correct horse"))
+
+	if err := NewEncryptedFileStore(filePath, "correct horse").Save(testCreds); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := NewEncryptedFileStore(filePath, "battery staple").Load(); err != ErrWrongPassphrase {
+		t.Fatalf("Load with wrong passphrase error = %v, want %v", err, ErrWrongPassphrase)
+	}
+}
+
+func TestEnvStore(t *testing.T) {
+	env := map[string]string{
+		EnvAppID:        testCreds.AppID,
+		EnvAppKey:       testCreds.AppKey,
+		EnvAccessToken:  testCreds.AccessToken,
+		EnvRefreshToken: testCreds.RefreshToken,
+		EnvTokenType:    testCreds.TokenType,
+		EnvTokenExpiry:  testCreds.Expiry.Format(time.RFC3339),
+	}
+	for k, v := range env {
+		os.Setenv(k, v)
+		defer os.Unsetenv(k)
+	}
+
+	store := NewEnvStore()
+	creds, err := store.Load()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if creds.AccessToken != testCreds.AccessToken || !creds.Expiry.Equal(testCreds.Expiry) {
+		t.Fatalf("loaded %#v, want %#v", creds, testCreds)
+	}
+
+	if err := store.Save(creds); !errors.Is(err, ErrReadOnlyStore) {
+		t.Fatalf("Save error = %v, want %v", err, ErrReadOnlyStore)
+	}
+}
@@ -0,0 +1,77 @@
+package config
+
+import (
+	"context"
+	"sync"
+
+	"github.com/pkg/errors"
+	"golang.org/x/oauth2"
+)
+
+// persistingTokenSource wraps an oauth2.TokenSource and saves every token
+// it has not seen before to a TokenStore, so refreshed (and rotated) tokens
+// survive a restart.
+type persistingTokenSource struct {
+	mu    sync.Mutex
+	src   oauth2.TokenSource
+	creds Credentials
+	store TokenStore
+	last  *oauth2.Token
+}
+
+// Returns a token from the wrapped source. When the token differs from the
+// last one seen, it is saved to the store before being handed out. Stores
+// that are read-only keep the new token in memory only.
+func (s *persistingTokenSource) Token() (*oauth2.Token, error) {
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	token, err := s.src.Token()
+	if err != nil {
+		return nil, err
+	}
+
+	if s.last != nil &&
+		s.last.AccessToken == token.AccessToken &&
+		s.last.RefreshToken == token.RefreshToken {
+		return token, nil
+	}
+
+	s.creds.SetToken(token)
+	if err := s.store.Save(&s.creds); err != nil && !errors.Is(err, ErrReadOnlyStore) {
+		return nil, err
+	}
+	s.last = token
+
+	return token, nil
+}
+
+// Returns an oauth2.TokenSource that refreshes the token stored in the
+// *config struct and saves each new token to `store`.
+func (c *config) tokenSource(ctx context.Context, store TokenStore) oauth2.TokenSource {
+
+	if c.OAuth2Conf == nil {
+		c.getOauthConf()
+	}
+
+	token := c.Token()
+
+	return &persistingTokenSource{
+		src:   c.OAuth2Conf.TokenSource(ctx, token),
+		creds: c.Credentials,
+		store: store,
+		last:  token,
+	}
+}
+
+// errTokenSource is an oauth2.TokenSource that always fails with err, so
+// clients built from a config that could not be loaded fail on their first
+// request instead of panicking.
+type errTokenSource struct {
+	err error
+}
+
+func (s errTokenSource) Token() (*oauth2.Token, error) {
+	return nil, s.err
+}
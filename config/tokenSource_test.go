@@ -0,0 +1,59 @@
+package config
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"golang.org/x/oauth2"
+)
+
+func TestPersistingTokenSource(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"access_token": "new-access", "refresh_token": "rotated-refresh", "token_type": "Bearer", "expires_in": 3600}`))
+	}))
+	defer srv.Close()
+
+	filePath := filepath.Join(t.TempDir(), "go-inoreader.json")
+	cfg := &config{
+		Credentials: Credentials{
+			AppID:        "1000000000",
+			AppKey:       "secret",
+			AccessToken:  "old-access",
+			RefreshToken: "old-refresh",
+			TokenType:    "Bearer",
+			Expiry:       time.Now().Add(-time.Hour),
+		},
+		OAuth2Conf: &oauth2.Config{
+			ClientID:     "1000000000",
+			ClientSecret: "secret",
+			Endpoint:     oauth2.Endpoint{TokenURL: srv.URL},
+		},
+	}
+
+	token, err := cfg.tokenSource(context.Background(), NewFileStore(filePath)).Token()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if token.AccessToken != "new-access" {
+		t.Fatalf("AccessToken %q, want new-access", token.AccessToken)
+	}
+
+	saved, err := loadConfig(filePath)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if saved.AccessToken != "new-access" || saved.RefreshToken != "rotated-refresh" {
+		t.Fatalf("saved tokens %q/%q, want new-access/rotated-refresh", saved.AccessToken, saved.RefreshToken)
+	}
+
+	if saved.AppID != cfg.AppID || saved.AppKey != cfg.AppKey {
+		t.Fatalf("saved app credentials %q/%q, want %q/%q", saved.AppID, saved.AppKey, cfg.AppID, cfg.AppKey)
+	}
+}
@@ -0,0 +1,16 @@
+package inoreader
+
+import "github.com/hyperreal64/go-inoreader/api"
+
+// APIError is returned by every call when the Inoreader API answers with a
+// status outside the 2xx range. See api.APIError.
+type APIError = api.APIError
+
+// Status checks for errors returned by Client methods.
+var (
+	IsUnauthorized = api.IsUnauthorized
+	IsForbidden    = api.IsForbidden
+	IsNotFound     = api.IsNotFound
+	IsRateLimited  = api.IsRateLimited
+	IsServerError  = api.IsServerError
+)
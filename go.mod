@@ -5,5 +5,6 @@ go 1.15
 require (
 	github.com/go-resty/resty/v2 v2.5.0
 	github.com/pkg/errors v0.9.1
+	golang.org/x/crypto v0.0.0-20210220033148-5ea612d1eb83
 	golang.org/x/oauth2 v0.0.0-20210220000619-9bb904979d93
 )
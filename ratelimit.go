@@ -0,0 +1,256 @@
+package inoreader
+
+import (
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/go-resty/resty/v2"
+	"github.com/pkg/errors"
+)
+
+// Zone is one of the Inoreader API's daily request quotas.
+type Zone int
+
+// Inoreader counts reads and writes against separate zones.
+const (
+	ZoneRead  Zone = 1
+	ZoneWrite Zone = 2
+)
+
+func (z Zone) String() string {
+
+	switch z {
+	case ZoneRead:
+		return "read"
+	case ZoneWrite:
+		return "write"
+	default:
+		return "zone" + strconv.Itoa(int(z))
+	}
+}
+
+// Endpoint paths whose calls count against ZoneWrite. Everything else is a
+// read.
+var writeEndpoints = []string{
+	"edit-tag",
+	"mark-all-as-read",
+	"rename-tag",
+	"disable-tag",
+	"subscription/edit",
+	"subscription/quickadd",
+	"preference/stream/set",
+}
+
+// Returns the zone a request to `rawURL` is counted against.
+func zoneOf(rawURL string) Zone {
+
+	p := rawURL
+	if u, err := url.Parse(rawURL); err == nil {
+		p = u.Path
+	}
+	p = strings.TrimRight(p, "/")
+
+	for _, endpoint := range writeEndpoints {
+		if p == endpoint || strings.HasSuffix(p, "/"+endpoint) {
+			return ZoneWrite
+		}
+	}
+
+	return ZoneRead
+}
+
+// ErrRateBudgetExhausted is returned, before anything is sent, for a
+// request whose zone has no more calls left than the reserve set with
+// WithRateLimitReserve.
+var ErrRateBudgetExhausted = errors.New("Inoreader rate limit budget exhausted")
+
+// BudgetSnapshot is the state of a zone as last reported by the API.
+type BudgetSnapshot struct {
+	Zone      Zone
+	Usage     int
+	Limit     int
+	Remaining int
+	ResetAt   time.Time
+
+	// Known is false until a response has carried the zone's headers.
+	Known bool
+}
+
+type budgetThreshold struct {
+	remaining int
+	fn        func(BudgetSnapshot)
+	fired     bool
+}
+
+// RateBudget tracks one zone's usage from the X-Reader-Zone*-Usage/Limit
+// and X-Reader-Limits-Reset-After headers of every response. It is safe for
+// concurrent use.
+type RateBudget struct {
+	mu         sync.Mutex
+	snap       BudgetSnapshot
+	reserve    int
+	thresholds []*budgetThreshold
+}
+
+func newRateBudget(zone Zone) *RateBudget {
+	return &RateBudget{snap: BudgetSnapshot{Zone: zone}}
+}
+
+// Snapshot returns the zone's state as last reported by the API.
+func (b *RateBudget) Snapshot() BudgetSnapshot {
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	return b.snap
+}
+
+// Remaining returns how many calls are left in the zone. `known` is false
+// until a response has reported the zone's usage.
+func (b *RateBudget) Remaining() (remaining int, known bool) {
+
+	s := b.Snapshot()
+	return s.Remaining, s.Known
+}
+
+// OnThreshold calls fn once when the zone's remaining calls drop to
+// `remaining` or below. It is armed again after the remaining calls go back
+// above `remaining`, typically when the limits reset. fn runs on the
+// goroutine that received the response and must not block.
+func (b *RateBudget) OnThreshold(remaining int, fn func(BudgetSnapshot)) {
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.thresholds = append(b.thresholds, &budgetThreshold{remaining: remaining, fn: fn})
+}
+
+// Records the usage reported in `h`, if any, and fires crossed thresholds.
+func (b *RateBudget) update(h http.Header, now time.Time) {
+
+	prefix := "X-Reader-Zone" + strconv.Itoa(int(b.snap.Zone))
+	usage, errUsage := strconv.Atoi(h.Get(prefix + "-Usage"))
+	limit, errLimit := strconv.Atoi(h.Get(prefix + "-Limit"))
+	if errUsage != nil || errLimit != nil {
+		return
+	}
+
+	b.mu.Lock()
+
+	b.snap.Usage = usage
+	b.snap.Limit = limit
+	b.snap.Remaining = limit - usage
+	if b.snap.Remaining < 0 {
+		b.snap.Remaining = 0
+	}
+	b.snap.Known = true
+
+	if reset, err := strconv.ParseFloat(h.Get("X-Reader-Limits-Reset-After"), 64); err == nil {
+		b.snap.ResetAt = now.Add(time.Duration(reset * float64(time.Second)))
+	}
+
+	snap := b.snap
+	var fire []func(BudgetSnapshot)
+	for _, t := range b.thresholds {
+		switch {
+		case snap.Remaining <= t.remaining && !t.fired:
+			t.fired = true
+			fire = append(fire, t.fn)
+		case snap.Remaining > t.remaining:
+			t.fired = false
+		}
+	}
+
+	b.mu.Unlock()
+
+	for _, fn := range fire {
+		fn(snap)
+	}
+}
+
+// Returns ErrRateBudgetExhausted if the zone has no calls left beyond the
+// reserve. Once the reported reset time has passed, the request is let
+// through to learn the new usage.
+func (b *RateBudget) check(now time.Time) error {
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if b.reserve <= 0 || !b.snap.Known {
+		return nil
+	}
+
+	if !b.snap.ResetAt.IsZero() && now.After(b.snap.ResetAt) {
+		return nil
+	}
+
+	if b.snap.Remaining <= b.reserve {
+		return errors.Wrapf(ErrRateBudgetExhausted, "%s zone: %d of %d calls left, %d reserved",
+			b.snap.Zone, b.snap.Remaining, b.snap.Limit, b.reserve)
+	}
+
+	return nil
+}
+
+// WithRateLimitReserve makes requests counted against `zone` fail fast
+// with ErrRateBudgetExhausted once only `reserve` calls are left, keeping
+// them for the caller's own use (for example, from another Client). A
+// reserve of 0 spends the budget down to the API's own 429.
+func WithRateLimitReserve(zone Zone, reserve int) Option {
+	return func(c *Client) {
+		c.budget(zone).reserve = reserve
+	}
+}
+
+// Returns the RateBudget of `zone`, creating it if needed. Only called
+// while the Client is being built.
+func (c *Client) budget(zone Zone) *RateBudget {
+
+	if c.budgets == nil {
+		c.budgets = make(map[Zone]*RateBudget)
+	}
+
+	b, ok := c.budgets[zone]
+	if !ok {
+		b = newRateBudget(zone)
+		c.budgets[zone] = b
+	}
+
+	return b
+}
+
+// RateBudget returns the tracker for `zone`, or nil for an unknown zone.
+func (c *Client) RateBudget(zone Zone) *RateBudget {
+	return c.budgets[zone]
+}
+
+// Resty OnBeforeRequest middleware enforcing WithRateLimitReserve.
+func (c *Client) checkRateBudget(rc *resty.Client, req *resty.Request) error {
+
+	if b := c.budgets[zoneOf(req.URL)]; b != nil {
+		return b.check(time.Now())
+	}
+
+	return nil
+}
+
+// Resty OnAfterResponse middleware recording the zone headers.
+func (c *Client) updateRateBudgets(rc *resty.Client, resp *resty.Response) error {
+
+	c.recordRateHeaders(resp.Header())
+	return nil
+}
+
+// Records the zone headers of a response in every budget. The retry
+// transport calls it for the responses it drops.
+func (c *Client) recordRateHeaders(h http.Header) {
+
+	now := time.Now()
+	for _, b := range c.budgets {
+		b.update(h, now)
+	}
+}
@@ -0,0 +1,91 @@
+package inoreader
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"sync/atomic"
+	"testing"
+
+	"github.com/pkg/errors"
+)
+
+func TestZoneOf(t *testing.T) {
+	cases := map[string]Zone{
+		"edit-tag":                          ZoneWrite,
+		"subscription/quickadd?quickadd=x":  ZoneWrite,
+		"http://h/reader/api/0/rename-tag":  ZoneWrite,
+		"user-info":                         ZoneRead,
+		"stream/contents/feed%2Fhttp%3A%2F": ZoneRead,
+		"subscription/list":                 ZoneRead,
+	}
+
+	for rawURL, want := range cases {
+		if got := zoneOf(rawURL); got != want {
+			t.Fatalf("zoneOf(%q) = %s, want %s", rawURL, got, want)
+		}
+	}
+}
+
+func TestRateBudget(t *testing.T) {
+	var writeUsage int32 = 47
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("X-Reader-Zone1-Usage", "10")
+		w.Header().Set("X-Reader-Zone1-Limit", "100")
+		w.Header().Set("X-Reader-Zone2-Usage", strconv.Itoa(int(atomic.LoadInt32(&writeUsage))))
+		w.Header().Set("X-Reader-Zone2-Limit", "50")
+		w.Header().Set("X-Reader-Limits-Reset-After", "3600")
+		if r.Method == http.MethodPost {
+			w.Header().Set("X-Reader-Zone2-Usage", strconv.Itoa(int(atomic.AddInt32(&writeUsage, 1))))
+			w.Write([]byte("OK"))
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"userId": "1005869311", "userName": "hyperreal"}`))
+	}))
+	defer srv.Close()
+
+	c := NewClient(WithBaseURL(srv.URL), WithRateLimitReserve(ZoneWrite, 1))
+
+	var fired []int
+	c.RateBudget(ZoneWrite).OnThreshold(2, func(s BudgetSnapshot) {
+		fired = append(fired, s.Remaining)
+	})
+
+	if remaining, known := c.RateBudget(ZoneRead).Remaining(); known {
+		t.Fatalf("read budget known (%d left) before any request", remaining)
+	}
+
+	if _, err := c.GetUserInfo(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+
+	if s := c.RateBudget(ZoneRead).Snapshot(); !s.Known || s.Remaining != 90 || s.Limit != 100 || s.ResetAt.IsZero() {
+		t.Fatalf("read budget %#v, want 90 of 100 left with a reset time", s)
+	}
+
+	params := map[string]string{"a": "user/-/state/com.google/read", "i": "1"}
+
+	// Write usage goes to 48 and 49 of 50: the first call reaches the
+	// threshold and the second leaves only the reserve, so the third fails
+	// fast.
+	for i := 0; i < 2; i++ {
+		if err := c.EditTag(context.Background(), params); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if len(fired) != 1 || fired[0] != 2 {
+		t.Fatalf("threshold fired with %v, want [2]", fired)
+	}
+
+	if err := c.EditTag(context.Background(), params); !errors.Is(err, ErrRateBudgetExhausted) {
+		t.Fatalf("EditTag error = %v, want %v", err, ErrRateBudgetExhausted)
+	}
+
+	if got := atomic.LoadInt32(&writeUsage); got != 49 {
+		t.Fatalf("server saw write usage %d, want 49", got)
+	}
+}
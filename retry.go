@@ -0,0 +1,291 @@
+package inoreader
+
+import (
+	"io"
+	"io/ioutil"
+	"math/rand"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Defaults for the zero fields of a RetryPolicy.
+const (
+	DefaultMaxAttempts = 3
+	DefaultBaseDelay   = 500 * time.Millisecond
+	DefaultMaxDelay    = 30 * time.Second
+)
+
+// RetryPolicy controls how requests that fail with a transport error or a
+// 429, 500, 502, 503 or 504 status are retried.
+//
+// GET, HEAD and OPTIONS requests are always retried, and so are POSTs to
+// endpoints that only read, such as stream/items/contents. Other POST
+// requests are only retried for the endpoint paths in IdempotentEndpoints,
+// since most Inoreader writes (edit-tag, subscription/quickadd, ...) would
+// otherwise be applied twice when the first attempt did reach the server.
+type RetryPolicy struct {
+	// MaxAttempts is the number of attempts, including the first one.
+	MaxAttempts int
+
+	// BaseDelay is the wait before the first retry. It doubles with every
+	// retry up to MaxDelay, and half of it is randomised.
+	BaseDelay time.Duration
+	MaxDelay  time.Duration
+
+	// IdempotentEndpoints lists endpoint paths, such as "edit-tag", whose
+	// POST requests are safe to send again.
+	IdempotentEndpoints []string
+
+	// OnRetry, if set, is called before waiting for each retry.
+	OnRetry func(RetryEvent)
+}
+
+// RetryEvent describes a failed attempt that is about to be retried.
+type RetryEvent struct {
+	// Attempt is the number of the attempt that failed, starting at 1.
+	Attempt  int
+	Method   string
+	Endpoint string
+
+	// StatusCode is the status of the failed attempt, or 0 if Err is set.
+	StatusCode int
+	Err        error
+
+	// Delay is how long the transport waits before the next attempt. It
+	// comes from the Retry-After header when the response has one.
+	Delay time.Duration
+}
+
+// Endpoints read through POST requests, which are safe to send again.
+var readOnlyPostEndpoints = []string{
+	"stream/items/contents",
+}
+
+// Returns p with defaults filled in for zero fields.
+func (p RetryPolicy) withDefaults() RetryPolicy {
+
+	if p.MaxAttempts <= 0 {
+		p.MaxAttempts = DefaultMaxAttempts
+	}
+
+	if p.BaseDelay <= 0 {
+		p.BaseDelay = DefaultBaseDelay
+	}
+
+	if p.MaxDelay <= 0 {
+		p.MaxDelay = DefaultMaxDelay
+	}
+
+	return p
+}
+
+// WithRetryPolicy retries failed requests according to p. Without it every
+// request is sent once.
+func WithRetryPolicy(p RetryPolicy) Option {
+	return func(c *Client) {
+		c.retry = &p
+	}
+}
+
+type retryTransport struct {
+	base   http.RoundTripper
+	policy RetryPolicy
+
+	// discarded, if set, is given the headers of every response that is
+	// retried, and so never reaches the caller.
+	discarded func(http.Header)
+
+	mu  sync.Mutex
+	rnd *rand.Rand
+}
+
+// NewRetryTransport returns an http.RoundTripper that sends requests through
+// base, or http.DefaultTransport if base is nil, and retries them according
+// to p. NewClient uses it for WithRetryPolicy; it can also wrap the
+// transport of the *http.Client behind the package-level functions.
+//
+// Responses that are retried are dropped by the transport. With NewClient
+// their rate limit headers still reach the Client's RateBudgets; a
+// transport built here on its own has no budgets to report them to.
+func NewRetryTransport(base http.RoundTripper, p RetryPolicy) http.RoundTripper {
+	return newRetryTransport(base, p)
+}
+
+func newRetryTransport(base http.RoundTripper, p RetryPolicy) *retryTransport {
+
+	if base == nil {
+		base = http.DefaultTransport
+	}
+
+	return &retryTransport{
+		base:   base,
+		policy: p.withDefaults(),
+		rnd:    rand.New(rand.NewSource(time.Now().UnixNano())),
+	}
+}
+
+// RoundTrip sends a copy of req for every attempt, leaving req itself
+// unchanged as the http.RoundTripper contract requires.
+func (t *retryTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+
+	canRetry := t.retryable(req)
+
+	for attempt := 1; ; attempt++ {
+		r := req.Clone(req.Context())
+		if attempt > 1 && req.Body != nil && req.Body != http.NoBody {
+			body, err := req.GetBody()
+			if err != nil {
+				return nil, err
+			}
+			r.Body = body
+		}
+
+		resp, err := t.base.RoundTrip(r)
+		if !canRetry || attempt >= t.policy.MaxAttempts || req.Context().Err() != nil {
+			return resp, err
+		}
+
+		event := RetryEvent{
+			Attempt:  attempt,
+			Method:   req.Method,
+			Endpoint: req.URL.Path,
+			Err:      err,
+		}
+
+		if err == nil {
+			if !retryStatus(resp.StatusCode) {
+				return resp, nil
+			}
+			event.StatusCode = resp.StatusCode
+
+			event.Delay = t.backoff(attempt)
+			if wait, ok := parseRetryAfter(resp.Header.Get("Retry-After"), time.Now()); ok {
+				// A wait beyond MaxDelay is usually a daily limit; hand the
+				// response back rather than block that long.
+				if wait > t.policy.MaxDelay {
+					return resp, nil
+				}
+				event.Delay = wait
+			}
+
+			if t.discarded != nil {
+				t.discarded(resp.Header)
+			}
+			io.Copy(ioutil.Discard, resp.Body)
+			resp.Body.Close()
+		} else {
+			event.Delay = t.backoff(attempt)
+		}
+
+		if t.policy.OnRetry != nil {
+			t.policy.OnRetry(event)
+		}
+
+		timer := time.NewTimer(event.Delay)
+		select {
+		case <-req.Context().Done():
+			timer.Stop()
+			return nil, req.Context().Err()
+		case <-timer.C:
+		}
+	}
+}
+
+// Reports whether req may be sent more than once.
+func (t *retryTransport) retryable(req *http.Request) bool {
+
+	if req.Body != nil && req.Body != http.NoBody && req.GetBody == nil {
+		return false
+	}
+
+	switch req.Method {
+	case http.MethodGet, http.MethodHead, http.MethodOptions:
+		return true
+	case http.MethodPost:
+		return matchEndpoint(req.URL.Path, readOnlyPostEndpoints) ||
+			matchEndpoint(req.URL.Path, t.policy.IdempotentEndpoints)
+	}
+
+	return false
+}
+
+// Reports whether path `p` is one of `endpoints`, given relative to the
+// API root.
+func matchEndpoint(p string, endpoints []string) bool {
+
+	p = strings.TrimRight(p, "/")
+	for _, endpoint := range endpoints {
+		endpoint = strings.Trim(endpoint, "/")
+		if p == endpoint || strings.HasSuffix(p, "/"+endpoint) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// Returns the wait before the retry following `attempt`: BaseDelay doubled
+// for every earlier retry, capped at MaxDelay, with the upper half jittered.
+func (t *retryTransport) backoff(attempt int) time.Duration {
+
+	d := t.policy.BaseDelay
+	for i := 1; i < attempt && d < t.policy.MaxDelay; i++ {
+		d *= 2
+	}
+	if d > t.policy.MaxDelay {
+		d = t.policy.MaxDelay
+	}
+
+	half := d / 2
+	if half <= 0 {
+		return d
+	}
+
+	t.mu.Lock()
+	jitter := time.Duration(t.rnd.Int63n(int64(half) + 1))
+	t.mu.Unlock()
+
+	return half + jitter
+}
+
+// Reports whether a response with status `code` is worth retrying.
+func retryStatus(code int) bool {
+
+	switch code {
+	case http.StatusTooManyRequests, http.StatusInternalServerError, http.StatusBadGateway,
+		http.StatusServiceUnavailable, http.StatusGatewayTimeout:
+		return true
+	}
+
+	return false
+}
+
+// Parses a Retry-After header given either in seconds or as an HTTP date.
+func parseRetryAfter(value string, now time.Time) (time.Duration, bool) {
+
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return 0, false
+	}
+
+	if secs, err := strconv.Atoi(value); err == nil {
+		if secs < 0 {
+			return 0, false
+		}
+		return time.Duration(secs) * time.Second, true
+	}
+
+	when, err := http.ParseTime(value)
+	if err != nil {
+		return 0, false
+	}
+
+	if wait := when.Sub(now); wait > 0 {
+		return wait, true
+	}
+
+	return 0, true
+}
@@ -0,0 +1,252 @@
+package inoreader
+
+import (
+	"context"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// Returns a server answering the first `failures` requests with `status`
+// and the rest with a user-info body, and a pointer to its request count.
+func flakyServer(t *testing.T, failures int32, status int, retryAfter string) (*httptest.Server, *int32) {
+
+	var requests int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodPost {
+			if r.FormValue("i") != "1" {
+				t.Errorf("attempt %d sent form %v", atomic.LoadInt32(&requests)+1, r.Form)
+			}
+		}
+
+		if atomic.AddInt32(&requests, 1) <= failures {
+			if retryAfter != "" {
+				w.Header().Set("Retry-After", retryAfter)
+			}
+			w.WriteHeader(status)
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"userId": "1005869311", "userName": "hyperreal"}`))
+	}))
+	t.Cleanup(srv.Close)
+
+	return srv, &requests
+}
+
+func TestRetryGET(t *testing.T) {
+	srv, requests := flakyServer(t, 2, http.StatusServiceUnavailable, "")
+
+	var events []RetryEvent
+	c := NewClient(WithBaseURL(srv.URL), WithRetryPolicy(RetryPolicy{
+		BaseDelay: time.Millisecond,
+		OnRetry:   func(e RetryEvent) { events = append(events, e) },
+	}))
+
+	userinfo, err := c.GetUserInfo(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if userinfo.UserName != "hyperreal" || *requests != 3 {
+		t.Fatalf("got %q after %d requests, want hyperreal after 3", userinfo.UserName, *requests)
+	}
+
+	if len(events) != 2 || events[0].Attempt != 1 || events[1].StatusCode != http.StatusServiceUnavailable || events[1].Endpoint != "/user-info" {
+		t.Fatalf("retry events %#v", events)
+	}
+}
+
+func TestRetryMaxAttempts(t *testing.T) {
+	srv, requests := flakyServer(t, 5, http.StatusServiceUnavailable, "")
+
+	c := NewClient(WithBaseURL(srv.URL), WithRetryPolicy(RetryPolicy{MaxAttempts: 2, BaseDelay: time.Millisecond}))
+
+	if _, err := c.GetUserInfo(context.Background()); !IsServerError(err) {
+		t.Fatalf("GetUserInfo error = %v, want a 503 APIError", err)
+	}
+
+	if *requests != 2 {
+		t.Fatalf("%d requests, want 2", *requests)
+	}
+}
+
+func TestRetryPOST(t *testing.T) {
+	params := map[string]string{"a": "user/-/state/com.google/read", "i": "1"}
+
+	srv, requests := flakyServer(t, 1, http.StatusTooManyRequests, "0")
+	c := NewClient(WithBaseURL(srv.URL), WithRetryPolicy(RetryPolicy{BaseDelay: time.Hour}))
+
+	if err := c.EditTag(context.Background(), params); !IsRateLimited(err) || *requests != 1 {
+		t.Fatalf("EditTag error = %v after %d requests, want a 429 APIError after 1", err, *requests)
+	}
+
+	var delay time.Duration = -1
+	srv, requests = flakyServer(t, 1, http.StatusTooManyRequests, "0")
+	c = NewClient(WithBaseURL(srv.URL), WithRetryPolicy(RetryPolicy{
+		BaseDelay:           time.Hour,
+		IdempotentEndpoints: []string{"edit-tag"},
+		OnRetry:             func(e RetryEvent) { delay = e.Delay },
+	}))
+
+	if err := c.EditTag(context.Background(), params); err != nil || *requests != 2 {
+		t.Fatalf("EditTag error = %v after %d requests, want nil after 2", err, *requests)
+	}
+
+	if delay != 0 {
+		t.Fatalf("retry delay %s, want the Retry-After of 0s", delay)
+	}
+}
+
+func TestRetryItemContents(t *testing.T) {
+	var requests int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost || r.URL.Path != "/stream/items/contents" {
+			t.Errorf("unexpected %s %s", r.Method, r.URL.Path)
+		}
+
+		if atomic.AddInt32(&requests, 1) == 1 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"items": [{"id": "tag:google.com,2005:reader/item/0000000000000001"}]}`))
+	}))
+	defer srv.Close()
+
+	c := NewClient(WithBaseURL(srv.URL), WithRetryPolicy(RetryPolicy{BaseDelay: time.Millisecond}))
+
+	items, err := c.GetItemsByID(context.Background(), []string{"1"}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(items.Items) != 1 || requests != 2 {
+		t.Fatalf("got %d items after %d requests, want 1 after 2", len(items.Items), requests)
+	}
+}
+
+func TestRetryCancel(t *testing.T) {
+	srv, requests := flakyServer(t, 5, http.StatusServiceUnavailable, "")
+
+	ctx, cancel := context.WithCancel(context.Background())
+	c := NewClient(WithBaseURL(srv.URL), WithRetryPolicy(RetryPolicy{
+		BaseDelay: time.Hour,
+		OnRetry:   func(RetryEvent) { cancel() },
+	}))
+
+	if _, err := c.GetUserInfo(ctx); !errors.Is(err, context.Canceled) || *requests != 1 {
+		t.Fatalf("GetUserInfo error = %v after %d requests, want %v after 1", err, *requests, context.Canceled)
+	}
+}
+
+func TestRetryAfterTooLong(t *testing.T) {
+	srv, requests := flakyServer(t, 1, http.StatusTooManyRequests, "86400")
+
+	c := NewClient(WithBaseURL(srv.URL), WithRetryPolicy(RetryPolicy{BaseDelay: time.Millisecond}))
+
+	if _, err := c.GetUserInfo(context.Background()); !IsRateLimited(err) || *requests != 1 {
+		t.Fatalf("GetUserInfo error = %v after %d requests, want a 429 APIError after 1", err, *requests)
+	}
+}
+
+type roundTripFunc func(*http.Request) (*http.Response, error)
+
+func (f roundTripFunc) RoundTrip(req *http.Request) (*http.Response, error) {
+	return f(req)
+}
+
+func TestRetryTransportCopiesRequest(t *testing.T) {
+	var sent []*http.Request
+	var bodies []string
+	base := roundTripFunc(func(r *http.Request) (*http.Response, error) {
+		b, _ := ioutil.ReadAll(r.Body)
+		sent = append(sent, r)
+		bodies = append(bodies, string(b))
+
+		status := http.StatusOK
+		if len(sent) == 1 {
+			status = http.StatusServiceUnavailable
+		}
+		return &http.Response{StatusCode: status, Header: http.Header{}, Body: ioutil.NopCloser(strings.NewReader(""))}, nil
+	})
+
+	rt := NewRetryTransport(base, RetryPolicy{BaseDelay: time.Millisecond, IdempotentEndpoints: []string{"edit-tag"}})
+
+	req, err := http.NewRequest(http.MethodPost, "https://www.inoreader.com/reader/api/0/edit-tag", strings.NewReader("i=1"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	body := req.Body
+
+	resp, err := rt.RoundTrip(req)
+	if err != nil || resp.StatusCode != http.StatusOK {
+		t.Fatalf("RoundTrip = %v, %v", resp, err)
+	}
+
+	if len(sent) != 2 || sent[0] == req || sent[1] == req || req.Body != body {
+		t.Fatalf("sent %d requests, the caller's among them or with its body replaced", len(sent))
+	}
+
+	if bodies[0] != "i=1" || bodies[1] != "i=1" {
+		t.Fatalf("sent bodies %q", bodies)
+	}
+}
+
+func TestRetryRecordsRateBudget(t *testing.T) {
+	var requests int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if atomic.AddInt32(&requests, 1) == 1 {
+			w.Header().Set("X-Reader-Zone1-Usage", "99")
+			w.Header().Set("X-Reader-Zone1-Limit", "100")
+			w.WriteHeader(http.StatusTooManyRequests)
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"userId": "1005869311", "userName": "hyperreal"}`))
+	}))
+	defer srv.Close()
+
+	c := NewClient(WithBaseURL(srv.URL), WithRetryPolicy(RetryPolicy{BaseDelay: time.Millisecond}))
+
+	if _, err := c.GetUserInfo(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+
+	if s := c.RateBudget(ZoneRead).Snapshot(); !s.Known || s.Remaining != 1 || requests != 2 {
+		t.Fatalf("read budget %#v after %d requests, want 1 left from the retried response", s, requests)
+	}
+}
+
+func TestParseRetryAfter(t *testing.T) {
+	now := time.Date(2021, 3, 1, 12, 0, 0, 0, time.UTC)
+
+	cases := []struct {
+		value string
+		wait  time.Duration
+		ok    bool
+	}{
+		{"120", 2 * time.Minute, true},
+		{"Mon, 01 Mar 2021 12:00:30 GMT", 30 * time.Second, true},
+		{"Mon, 01 Mar 2021 11:00:00 GMT", 0, true},
+		{"", 0, false},
+		{"-1", 0, false},
+		{"soon", 0, false},
+	}
+
+	for _, tc := range cases {
+		wait, ok := parseRetryAfter(tc.value, now)
+		if wait != tc.wait || ok != tc.ok {
+			t.Fatalf("parseRetryAfter(%q) = %s, %v, want %s, %v", tc.value, wait, ok, tc.wait, tc.ok)
+		}
+	}
+}
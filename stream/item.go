@@ -0,0 +1,251 @@
+package stream
+
+import (
+	"bytes"
+	"encoding/json"
+	"strconv"
+
+	"github.com/hyperreal64/go-inoreader/api"
+)
+
+// Link JSON response, as in an item's canonical and alternate links.
+type Link struct {
+	Href string `json:"href"`
+	Type string `json:"type,omitempty"`
+}
+
+// Content JSON response; the HTML of an item's summary or content.
+type Content struct {
+	// Direction is the text direction, "ltr" or "rtl".
+	Direction string `json:"direction"`
+	Content   string `json:"content"`
+}
+
+// Enclosure JSON response; a media file attached to an item, such as a
+// podcast episode.
+type Enclosure struct {
+	Href string `json:"href"`
+
+	// Type is the MIME type, such as "audio/mpeg".
+	Type string `json:"type"`
+
+	// Length is the size in bytes, or 0 if the feed did not give one.
+	Length api.FlexInt `json:"length"`
+}
+
+// Visual JSON response; the image picked to illustrate an item.
+type Visual struct {
+	URL         string      `json:"url"`
+	Width       api.FlexInt `json:"width,omitempty"`
+	Height      api.FlexInt `json:"height,omitempty"`
+	ContentType string      `json:"contentType,omitempty"`
+}
+
+// LikingUser JSON response. The API lists liking users either as objects
+// or as bare user IDs; both decode into UserID.
+type LikingUser struct {
+	UserID   string `json:"userId"`
+	UserName string `json:"userName,omitempty"`
+}
+
+func (u *LikingUser) UnmarshalJSON(data []byte) error {
+
+	data = bytes.TrimSpace(data)
+	switch {
+	case bytes.Equal(data, []byte("null")):
+		return nil
+	case len(data) > 0 && data[0] == '"':
+		*u = LikingUser{}
+		return json.Unmarshal(data, &u.UserID)
+	case len(data) > 0 && data[0] != '{':
+		var id api.FlexInt
+		if err := json.Unmarshal(data, &id); err != nil {
+			return err
+		}
+		*u = LikingUser{UserID: strconv.FormatInt(id.Int64(), 10)}
+		return nil
+	}
+
+	// A distinct type, so that decoding does not recurse into this method.
+	type likingUser LikingUser
+	return json.Unmarshal(data, (*likingUser)(u))
+}
+
+// Comment JSON response; a comment left on a broadcast item.
+type Comment struct {
+	ID       api.FlexInt `json:"id"`
+	UserID   api.FlexInt `json:"userId"`
+	UserName string      `json:"userName"`
+	Text     string      `json:"text"`
+
+	// Date is the time the comment was posted.
+	Date api.UnixTime `json:"date"`
+}
+
+// Annotation JSON response; a highlight the user made in an item, with an
+// optional note.
+type Annotation struct {
+	ID api.FlexInt `json:"id"`
+
+	// Start and End are the highlight's offsets in the item's text.
+	Start api.FlexInt `json:"start"`
+	End   api.FlexInt `json:"end"`
+
+	// AddedOn is the time the annotation was made.
+	AddedOn api.UnixTime `json:"added_on"`
+
+	Text               string      `json:"text"`
+	Note               string      `json:"note"`
+	UserID             api.FlexInt `json:"user_id"`
+	UserName           string      `json:"user_name"`
+	UserProfilePicture string      `json:"user_profile_picture"`
+}
+
+// Body returns the HTML of the item's full content if the API sent one,
+// and its summary otherwise.
+func (i *Item) Body() string {
+
+	if i.Content != nil && i.Content.Content != "" {
+		return i.Content.Content
+	}
+
+	return i.Summary.Content
+}
+
+// URL returns the item's link: the first canonical link, or the first
+// alternate link if it has no canonical one.
+func (i *Item) URL() string {
+
+	for _, links := range [][]Link{i.Canonical, i.Alternate} {
+		for _, l := range links {
+			if l.Href != "" {
+				return l.Href
+			}
+		}
+	}
+
+	return ""
+}
+
+// Reports whether the item's categories include state `s`.
+func (i *Item) hasState(s StreamState) bool {
+
+	for _, c := range i.Categories {
+		if state, ok := c.State(); ok && state == s {
+			return true
+		}
+	}
+
+	return false
+}
+
+// IsRead reports whether the item is marked as read.
+func (i *Item) IsRead() bool {
+	return i.hasState(Read)
+}
+
+// IsStarred reports whether the item is starred.
+func (i *Item) IsStarred() bool {
+	return i.hasState(Starred)
+}
+
+// IsBroadcast reports whether the user broadcast the item.
+func (i *Item) IsBroadcast() bool {
+	return i.hasState(Broadcast)
+}
+
+// IsLiked reports whether the user liked the item.
+func (i *Item) IsLiked() bool {
+	return i.hasState(Like)
+}
+
+// Labels returns the names of every label in the item's categories: the
+// folder of its feed as well as the tags put on the item itself. The API
+// writes both the same way; Folders and Tags tell them apart.
+func (i *Item) Labels() []string {
+
+	var labels []string
+	for _, c := range i.Categories {
+		if name, ok := c.Label(); ok {
+			labels = append(labels, name)
+		}
+	}
+
+	return labels
+}
+
+// FolderSet holds the names of the labels that are folders, as opposed to
+// tags. tags.TagFolderList.FolderSet builds one from the tag list.
+type FolderSet map[string]bool
+
+// NewFolderSet returns the FolderSet of the given folder streams. IDs that
+// are not labels are ignored.
+func NewFolderSet(folders ...StreamID) FolderSet {
+
+	fs := make(FolderSet, len(folders))
+	for _, id := range folders {
+		if name, ok := id.Label(); ok {
+			fs[name] = true
+		}
+	}
+
+	return fs
+}
+
+// Folders returns the item's labels that are folders in `folders`.
+func (i *Item) Folders(folders FolderSet) []string {
+
+	var names []string
+	for _, name := range i.Labels() {
+		if folders[name] {
+			names = append(names, name)
+		}
+	}
+
+	return names
+}
+
+// Tags returns the item's labels that are not folders in `folders`.
+func (i *Item) Tags(folders FolderSet) []string {
+
+	var names []string
+	for _, name := range i.Labels() {
+		if !folders[name] {
+			names = append(names, name)
+		}
+	}
+
+	return names
+}
+
+// GroupByOrigin groups items by the stream they came from, keeping their
+// order within each group. Items without an origin are grouped under "".
+// User streams are keyed in their "user/-/" form.
+func GroupByOrigin(items []Item) map[StreamID][]Item {
+
+	groups := make(map[StreamID][]Item)
+	for _, item := range items {
+		var origin StreamID
+		if item.Origin != nil {
+			origin = item.Origin.StreamID.Generic()
+		}
+		groups[origin] = append(groups[origin], item)
+	}
+
+	return groups
+}
+
+// GroupByLabel groups items by label name, keeping their order within each
+// group. An item with several labels is in each of their groups, and items
+// without labels are left out.
+func GroupByLabel(items []Item) map[string][]Item {
+
+	groups := make(map[string][]Item)
+	for _, item := range items {
+		for _, name := range item.Labels() {
+			groups[name] = append(groups[name], item)
+		}
+	}
+
+	return groups
+}
@@ -0,0 +1,110 @@
+package stream
+
+import (
+	"encoding/json"
+	"fmt"
+	"testing"
+)
+
+const itemJSON = `{
+	"crawlTimeMsec": "1614556800123",
+	"timestampUsec": "1614556800123456",
+	"id": "tag:google.com,2005:reader/item/00000000000004d2",
+	"categories": ["user/1005869311/state/com.google/reading-list", "user/1005869311/label/Podcasts"],
+	"title": "Episode 42",
+	"published": 1614556800,
+	"updated": "1614556900",
+	"canonical": [{"href": ""}],
+	"alternate": [{"href": "https://example.com/42", "type": "text/html"}],
+	"summary": {"direction": "ltr", "content": "<p>Short</p>"},
+	"content": {"direction": "rtl", "content": "<p>Long</p>"},
+	"enclosure": [{"href": "https://example.com/42.mp3", "type": "audio/mpeg", "length": "1048576"}],
+	"visual": {"url": "https://example.com/42.jpg", "width": 640, "height": "480"},
+	"author": "Someone",
+	"likingUsers": [{"userId": "1001", "userName": "a"}, "1002", 1003],
+	"comments": [{"id": 7, "userId": "1001", "userName": "a", "text": "Nice", "date": 1614557000}],
+	"commentsNum": 1,
+	"annotations": [{"id": 9, "start": 0, "end": 5, "added_on": 1614557100, "text": "Short", "note": "note", "user_id": 1005869311, "user_name": "hyperreal"}],
+	"origin": {"streamId": "feed/https://example.com/feed", "title": "Example", "htmlUrl": "https://example.com"}
+}`
+
+func TestItemDecode(t *testing.T) {
+	var item Item
+	if err := json.Unmarshal([]byte(itemJSON), &item); err != nil {
+		t.Fatal(err)
+	}
+
+	if item.Updated.Sec() != 1614556900 || item.Published.Sec() != 1614556800 || item.TimestampUsec.Usec() != 1614556800123456 {
+		t.Fatalf("Updated %s, Published %s, TimestampUsec %s", item.Updated, item.Published, item.TimestampUsec)
+	}
+
+	if item.Body() != "<p>Long</p>" || item.Summary.Content != "<p>Short</p>" || item.Content.Direction != "rtl" {
+		t.Fatalf("Body() = %q, Summary %#v, Content %#v", item.Body(), item.Summary, item.Content)
+	}
+
+	if item.URL() != "https://example.com/42" {
+		t.Fatalf("URL() = %q", item.URL())
+	}
+
+	if e := item.Enclosure[0]; e.Type != "audio/mpeg" || e.Length != 1048576 {
+		t.Fatalf("Enclosure %#v", e)
+	}
+
+	if item.Visual == nil || item.Visual.Height != 480 {
+		t.Fatalf("Visual %#v", item.Visual)
+	}
+
+	if len(item.LikingUsers) != 3 || item.LikingUsers[0].UserName != "a" || item.LikingUsers[1].UserID != "1002" || item.LikingUsers[2].UserID != "1003" {
+		t.Fatalf("LikingUsers %#v", item.LikingUsers)
+	}
+
+	if c := item.Comments[0]; c.UserID != 1001 || c.Text != "Nice" || c.Date.Sec() != 1614557000 {
+		t.Fatalf("Comments %#v", item.Comments)
+	}
+
+	if a := item.Annotations[0]; a.End != 5 || a.Note != "note" || a.UserName != "hyperreal" {
+		t.Fatalf("Annotations %#v", item.Annotations)
+	}
+
+	out, err := json.Marshal(item)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var again Item
+	if err := json.Unmarshal(out, &again); err != nil {
+		t.Fatal(err)
+	}
+
+	if !again.Updated.Equal(item.Updated.Time) || !again.CrawlTimeMsec.Equal(item.CrawlTimeMsec.Time) || again.Enclosure[0].Length != item.Enclosure[0].Length || again.LikingUsers[2] != item.LikingUsers[2] {
+		t.Fatalf("round trip changed the item: %s", out)
+	}
+}
+
+func TestItemState(t *testing.T) {
+	items := []Item{
+		{ID: "1", Categories: []StreamID{"user/1005869311/state/com.google/read", "user/1005869311/label/Tech", "user/1005869311/label/Later"}, Origin: &Origin{StreamID: Feed("https://a.example/rss")}},
+		{ID: "2", Categories: []StreamID{State(Starred), State(Broadcast), Label("Tech")}, Origin: &Origin{StreamID: Feed("https://b.example/rss")}},
+		{ID: "3", Categories: []StreamID{State(Like)}, Origin: &Origin{StreamID: Feed("https://a.example/rss")}},
+		{ID: "4"},
+	}
+
+	if !items[0].IsRead() || items[0].IsStarred() || !items[1].IsStarred() || !items[1].IsBroadcast() || items[1].IsRead() || !items[2].IsLiked() {
+		t.Fatal("states do not match the categories")
+	}
+
+	folders := NewFolderSet(Label("Tech"), Feed("https://a.example/rss"))
+	if fmt.Sprint(items[0].Labels()) != "[Tech Later]" || fmt.Sprint(items[0].Folders(folders)) != "[Tech]" || fmt.Sprint(items[0].Tags(folders)) != "[Later]" {
+		t.Fatalf("Labels %v, Folders %v, Tags %v", items[0].Labels(), items[0].Folders(folders), items[0].Tags(folders))
+	}
+
+	byOrigin := GroupByOrigin(items)
+	if len(byOrigin) != 3 || len(byOrigin[Feed("https://a.example/rss")]) != 2 || byOrigin[Feed("https://a.example/rss")][1].ID != "3" || len(byOrigin[""]) != 1 {
+		t.Fatalf("GroupByOrigin = %v", byOrigin)
+	}
+
+	byLabel := GroupByLabel(items)
+	if len(byLabel) != 2 || len(byLabel["Tech"]) != 2 || byLabel["Tech"][0].ID != "1" || len(byLabel["Later"]) != 1 {
+		t.Fatalf("GroupByLabel = %v", byLabel)
+	}
+}
@@ -0,0 +1,177 @@
+package stream
+
+import (
+	"context"
+	"net/url"
+	"sync"
+
+	"github.com/go-resty/resty/v2"
+	"github.com/hyperreal64/go-inoreader/api"
+	"github.com/pkg/errors"
+)
+
+// Defaults for the zero fields of ItemsByIDOptions.
+const (
+	DefaultItemsChunkSize   = 250
+	DefaultItemsConcurrency = 4
+)
+
+// ItemsByIDOptions control how GetItemsByID splits its requests.
+type ItemsByIDOptions struct {
+	// ChunkSize is the number of IDs sent per request.
+	ChunkSize int
+
+	// Concurrency is the number of requests in flight at once.
+	Concurrency int
+
+	// Annotations asks for the items' annotations.
+	Annotations bool
+}
+
+// ItemsByID is the result of GetItemsByID.
+type ItemsByID struct {
+	// Items are the items found, in the order their IDs were requested.
+	Items []Item
+
+	// Missing are the requested IDs, as given, that the API returned no
+	// item for.
+	Missing []string
+}
+
+// Gets the full items with the given IDs, in either short or long form,
+// from stream/items/contents. The IDs are sent in chunks, several at a
+// time; the first failed chunk cancels the others and its error is
+// returned. Repeated IDs are only fetched and returned once.
+func GetItemsByID(ctx context.Context, rc *resty.Client, ids []string, opts *ItemsByIDOptions) (*ItemsByID, error) {
+
+	o := ItemsByIDOptions{}
+	if opts != nil {
+		o = *opts
+	}
+	if o.ChunkSize <= 0 {
+		o.ChunkSize = DefaultItemsChunkSize
+	}
+	if o.Concurrency <= 0 {
+		o.Concurrency = DefaultItemsConcurrency
+	}
+
+	var order []ItemID
+	given := make(map[ItemID]string, len(ids))
+	for _, s := range ids {
+		id, err := ParseItemID(s)
+		if err != nil {
+			return nil, err
+		}
+		if _, ok := given[id]; !ok {
+			given[id] = s
+			order = append(order, id)
+		}
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	var (
+		mu       sync.Mutex
+		found    = make(map[ItemID]Item, len(order))
+		firstErr error
+		wg       sync.WaitGroup
+		sem      = make(chan struct{}, o.Concurrency)
+	)
+
+	for start := 0; start < len(order); start += o.ChunkSize {
+		end := start + o.ChunkSize
+		if end > len(order) {
+			end = len(order)
+		}
+		chunk := order[start:end]
+
+		select {
+		case sem <- struct{}{}:
+		case <-ctx.Done():
+		}
+		if ctx.Err() != nil {
+			break
+		}
+
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			defer func() { <-sem }()
+
+			items, err := getItemsChunk(ctx, rc, chunk, o.Annotations)
+
+			mu.Lock()
+			defer mu.Unlock()
+
+			if err != nil {
+				if firstErr == nil {
+					firstErr = err
+					cancel()
+				}
+				return
+			}
+
+			for _, item := range items {
+				if id, err := ParseItemID(item.ID); err == nil {
+					found[id] = item
+				}
+			}
+		}()
+	}
+
+	wg.Wait()
+
+	if firstErr != nil {
+		return nil, firstErr
+	}
+
+	// The parent context may have ended between chunks.
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	result := &ItemsByID{Items: make([]Item, 0, len(found))}
+	for _, id := range order {
+		if item, ok := found[id]; ok {
+			result.Items = append(result.Items, item)
+		} else {
+			result.Missing = append(result.Missing, given[id])
+		}
+	}
+
+	return result, nil
+}
+
+// Fetches one chunk of items. Sends a POST request with one `i` form value
+// per ID.
+func getItemsChunk(ctx context.Context, rc *resty.Client, ids []ItemID, annotations bool) ([]Item, error) {
+
+	form := url.Values{}
+	for _, id := range ids {
+		form.Add("i", id.Long())
+	}
+
+	req := rc.R().
+		SetContext(ctx).
+		SetFormDataFromValues(form)
+	if annotations {
+		req.SetQueryParam("annotations", "1")
+	}
+
+	resp, err := req.Post(api.Endpoint(rc, itemContentsURL))
+	if err := api.CheckResponseContext(ctx, resp, err); err != nil {
+		return nil, err
+	}
+
+	var sc *StreamContents
+	if err := resty.Unmarshalc(rc, "application/json", resp.Body(), &sc); err != nil {
+		return nil, errors.Wrap(err, "Unable to unmarshal item contents")
+	}
+
+	if sc == nil {
+		return nil, nil
+	}
+
+	return sc.Items, nil
+}
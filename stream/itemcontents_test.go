@@ -0,0 +1,89 @@
+package stream
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+
+	"github.com/go-resty/resty/v2"
+)
+
+func TestGetItemsByID(t *testing.T) {
+	var (
+		mu       sync.Mutex
+		chunks   []int
+		inFlight int32
+		maxSeen  int32
+	)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		n := atomic.AddInt32(&inFlight, 1)
+		defer atomic.AddInt32(&inFlight, -1)
+		for {
+			seen := atomic.LoadInt32(&maxSeen)
+			if n <= seen || atomic.CompareAndSwapInt32(&maxSeen, seen, n) {
+				break
+			}
+		}
+
+		r.ParseForm()
+		mu.Lock()
+		chunks = append(chunks, len(r.PostForm["i"]))
+		mu.Unlock()
+
+		// Item 3 does not exist; return the rest in reverse order.
+		var items []string
+		ids := r.PostForm["i"]
+		for i := len(ids) - 1; i >= 0; i-- {
+			if ids[i] != "tag:google.com,2005:reader/item/0000000000000003" {
+				items = append(items, fmt.Sprintf(`{"id": %q}`, ids[i]))
+			}
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		fmt.Fprintf(w, `{"items": [%s]}`, strings.Join(items, ","))
+	}))
+	defer srv.Close()
+
+	rc := resty.New().SetHostURL(srv.URL)
+	ids := []string{"5", "tag:google.com,2005:reader/item/0000000000000001", "3", "2", "4", "1", "6"}
+
+	res, err := GetItemsByID(context.Background(), rc, ids, &ItemsByIDOptions{ChunkSize: 2, Concurrency: 2})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var got []string
+	for _, item := range res.Items {
+		id, _ := ShortItemID(item.ID)
+		got = append(got, id)
+	}
+
+	if fmt.Sprint(got) != "[5 1 2 4 6]" || fmt.Sprint(res.Missing) != "[3]" {
+		t.Fatalf("got items %v, missing %v; want [5 1 2 4 6], [3]", got, res.Missing)
+	}
+
+	if len(chunks) != 3 || maxSeen > 2 {
+		t.Fatalf("sent chunks %v with up to %d in flight, want 3 chunks, at most 2", chunks, maxSeen)
+	}
+}
+
+func TestGetItemsByIDError(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer srv.Close()
+
+	rc := resty.New().SetHostURL(srv.URL)
+	if _, err := GetItemsByID(context.Background(), rc, []string{"1", "2", "3"}, &ItemsByIDOptions{ChunkSize: 1}); err == nil {
+		t.Fatal("GetItemsByID succeeded against a failing server")
+	}
+
+	if _, err := GetItemsByID(context.Background(), rc, []string{"not-an-id"}, nil); err == nil {
+		t.Fatal("GetItemsByID accepted an invalid ID")
+	}
+}
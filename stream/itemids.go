@@ -0,0 +1,284 @@
+package stream
+
+import (
+	"context"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/go-resty/resty/v2"
+	"github.com/hyperreal64/go-inoreader/api"
+	"github.com/pkg/errors"
+)
+
+// Prefix of the long form of an item ID, as found in Item.ID.
+const longItemIDPrefix = "tag:google.com,2005:reader/item/"
+
+// ItemID is an Inoreader item ID. The API writes it either in short,
+// decimal form ("1234") in item references, or in long form
+// ("tag:google.com,2005:reader/item/00000000000004d2") in stream contents.
+type ItemID int64
+
+// ParseItemID parses an item ID given in either form.
+func ParseItemID(s string) (ItemID, error) {
+
+	if strings.HasPrefix(s, longItemIDPrefix) {
+		hex := strings.TrimPrefix(s, longItemIDPrefix)
+		id, err := strconv.ParseUint(hex, 16, 64)
+		if err != nil {
+			return 0, errors.Wrapf(err, "Invalid item ID %q", s)
+		}
+		return ItemID(id), nil
+	}
+
+	id, err := strconv.ParseInt(s, 10, 64)
+	if err != nil {
+		// Short IDs are signed, but some clients write them unsigned.
+		u, uerr := strconv.ParseUint(s, 10, 64)
+		if uerr != nil {
+			return 0, errors.Wrapf(err, "Invalid item ID %q", s)
+		}
+		id = int64(u)
+	}
+
+	return ItemID(id), nil
+}
+
+// Short returns the decimal form of the ID.
+func (id ItemID) Short() string {
+	return strconv.FormatInt(int64(id), 10)
+}
+
+// Long returns the tag:google.com,2005:reader/item/<hex> form of the ID,
+// with the hex part zero-padded to 16 digits.
+func (id ItemID) Long() string {
+
+	hex := strconv.FormatUint(uint64(id), 16)
+	return longItemIDPrefix + strings.Repeat("0", 16-len(hex)) + hex
+}
+
+func (id ItemID) String() string {
+	return id.Short()
+}
+
+// LongItemID converts an item ID in either form to the long form.
+func LongItemID(s string) (string, error) {
+
+	id, err := ParseItemID(s)
+	if err != nil {
+		return "", err
+	}
+
+	return id.Long(), nil
+}
+
+// ShortItemID converts an item ID in either form to the short form.
+func ShortItemID(s string) (string, error) {
+
+	id, err := ParseItemID(s)
+	if err != nil {
+		return "", err
+	}
+
+	return id.Short(), nil
+}
+
+// ItemID parses the reference's ID.
+func (r ItemRef) ItemID() (ItemID, error) {
+	return ParseItemID(r.ID)
+}
+
+// ItemIDsOptions are the parameters of a stream/items/ids request. They
+// mean the same as the fields of StreamContentsOptions, except that Count
+// goes up to MaxItemIDsCount.
+type ItemIDsOptions struct {
+	// StreamID is the stream to list. Empty means the reading list.
+	StreamID StreamID
+
+	Count        int
+	Order        Order
+	NewerThan    time.Time
+	OlderThan    time.Time
+	Include      []StreamID
+	Exclude      []StreamID
+	Continuation string
+
+	// IncludeAllDirectStreamIDs fills in ItemRef.DirectStreamIds.
+	IncludeAllDirectStreamIDs bool
+}
+
+// Returns the StreamContentsOptions sharing o's filters. Count is left out,
+// since the two endpoints have different limits.
+func (o *ItemIDsOptions) contentsOptions() *StreamContentsOptions {
+	return &StreamContentsOptions{
+		Order:        o.Order,
+		NewerThan:    o.NewerThan,
+		OlderThan:    o.OlderThan,
+		Include:      o.Include,
+		Exclude:      o.Exclude,
+		Continuation: o.Continuation,
+	}
+}
+
+// Validate reports the first invalid field of o, if any.
+func (o *ItemIDsOptions) Validate() error {
+
+	if err := validateCount(o.Count, MaxItemIDsCount); err != nil {
+		return err
+	}
+
+	return o.contentsOptions().Validate()
+}
+
+// Encode validates o and returns its query parameters.
+func (o *ItemIDsOptions) Encode() (url.Values, error) {
+
+	if err := o.Validate(); err != nil {
+		return nil, err
+	}
+
+	v, err := o.contentsOptions().Encode()
+	if err != nil {
+		return nil, err
+	}
+
+	if o.Count > 0 {
+		v.Set("n", strconv.Itoa(o.Count))
+	}
+
+	streamID := o.StreamID
+	if streamID == "" {
+		streamID = State(ReadingList)
+	}
+	v.Set("s", string(streamID))
+
+	if o.IncludeAllDirectStreamIDs {
+		v.Set("includeAllDirectStreamIds", "true")
+	}
+
+	return v, nil
+}
+
+// Gets the IDs of the items described by opts, without their content. A
+// nil opts is the same as the zero ItemIDsOptions.
+func GetItemIDs(ctx context.Context, rc *resty.Client, opts *ItemIDsOptions) (ids *ItemIDs, err error) {
+
+	if opts == nil {
+		opts = &ItemIDsOptions{}
+	}
+
+	params, err := opts.Encode()
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := rc.R().
+		SetContext(ctx).
+		SetQueryParamsFromValues(params).
+		Get(api.Endpoint(rc, itemIDsURL))
+	if err := api.CheckResponseContext(ctx, resp, err); err != nil {
+		return nil, err
+	}
+
+	if err := resty.Unmarshalc(rc, "application/json", resp.Body(), &ids); err != nil {
+		return nil, errors.Wrap(err, "Unable to unmarshal item IDs")
+	}
+
+	if !opts.OlderThan.IsZero() {
+		kept := ids.ItemRefs[:0]
+		for _, ref := range ids.ItemRefs {
+			if beforeBound(ref.TimestampUsec.Time, opts.OlderThan) {
+				kept = append(kept, ref)
+			}
+		}
+		ids.Continuation = boundedContinuation(ids.Continuation, opts.Order, len(kept) < len(ids.ItemRefs))
+		ids.ItemRefs = kept
+	}
+
+	return ids, nil
+}
+
+// ItemRefIterator yields the item references of a stream one by one,
+// fetching pages of stream/items/ids as needed. It is used like
+// ItemIterator.
+type ItemRefIterator struct {
+	ctx   context.Context
+	pager *Pager
+	max   int
+	n     int
+	buf   []ItemRef
+	cur   ItemRef
+	err   error
+}
+
+// NewItemRefIterator returns an iterator over the item references
+// described by opts, starting at opts.Continuation. `max` caps the total
+// number of references yielded; 0 means no cap.
+func NewItemRefIterator(ctx context.Context, rc *resty.Client, opts *ItemIDsOptions, max int) *ItemRefIterator {
+
+	o := ItemIDsOptions{}
+	if opts != nil {
+		o = *opts
+	}
+
+	it := &ItemRefIterator{ctx: ctx, max: max}
+	it.pager = NewPager(o.Continuation, func(ctx context.Context, continuation string) (string, error) {
+
+		page := o
+		page.Continuation = continuation
+		page.Count = pageSize(o.Count, it.max, it.n)
+
+		ids, err := GetItemIDs(ctx, rc, &page)
+		if err != nil {
+			return "", err
+		}
+
+		it.buf = ids.ItemRefs
+		return ids.Continuation, nil
+	})
+
+	return it
+}
+
+// Next advances to the next item reference. It returns false when the
+// stream is exhausted, the maximum count was reached, the context is done
+// or a request failed.
+func (it *ItemRefIterator) Next() bool {
+
+	if err := it.ctx.Err(); err != nil {
+		it.err = err
+		return false
+	}
+
+	if it.max > 0 && it.n >= it.max {
+		return false
+	}
+
+	for len(it.buf) == 0 {
+		if !it.pager.Next(it.ctx) {
+			return false
+		}
+	}
+
+	it.cur = it.buf[0]
+	it.buf = it.buf[1:]
+	it.n++
+
+	return true
+}
+
+// ItemRef returns the current item reference.
+func (it *ItemRefIterator) ItemRef() ItemRef {
+	return it.cur
+}
+
+// Err returns the error that stopped the iterator, if any.
+func (it *ItemRefIterator) Err() error {
+
+	if it.err != nil {
+		return it.err
+	}
+
+	return it.pager.Err()
+}
@@ -0,0 +1,138 @@
+package stream
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/go-resty/resty/v2"
+	"github.com/pkg/errors"
+)
+
+func TestItemID(t *testing.T) {
+	cases := []struct {
+		in    string
+		short string
+		long  string
+	}{
+		{"1234", "1234", "tag:google.com,2005:reader/item/00000000000004d2"},
+		{"tag:google.com,2005:reader/item/00000000000004d2", "1234", "tag:google.com,2005:reader/item/00000000000004d2"},
+		{"-1", "-1", "tag:google.com,2005:reader/item/ffffffffffffffff"},
+		{"18446744073709551615", "-1", "tag:google.com,2005:reader/item/ffffffffffffffff"},
+	}
+
+	for _, tc := range cases {
+		short, err := ShortItemID(tc.in)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		long, err := LongItemID(tc.in)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if short != tc.short || long != tc.long {
+			t.Fatalf("%q converts to %q and %q, want %q and %q", tc.in, short, long, tc.short, tc.long)
+		}
+	}
+
+	for _, in := range []string{"", "abc", "tag:google.com,2005:reader/item/xyz"} {
+		if _, err := ParseItemID(in); err == nil {
+			t.Fatalf("ParseItemID(%q) succeeded", in)
+		}
+	}
+}
+
+func TestGetItemIDs(t *testing.T) {
+	var gotQuery string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotQuery = r.URL.RawQuery
+
+		next := "page2"
+		refs := `{"id": "1", "directStreamIds": ["feed/http://a/"], "timestampUsec": "1614556800000000"}, {"id": "2"}`
+		if r.FormValue("c") == "page2" {
+			next = ""
+			refs = `{"id": "3"}`
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		fmt.Fprintf(w, `{"items": [], "itemRefs": [%s], "continuation": "%s"}`, refs, next)
+	}))
+	defer srv.Close()
+
+	rc := resty.New().SetHostURL(srv.URL)
+	ids, err := GetItemIDs(context.Background(), rc, &ItemIDsOptions{
+		Count:                     2,
+		Exclude:                   []StreamID{"user/-/state/com.google/read"},
+		IncludeAllDirectStreamIDs: true,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := "includeAllDirectStreamIds=true&n=2&s=user%2F-%2Fstate%2Fcom.google%2Freading-list&xt=user%2F-%2Fstate%2Fcom.google%2Fread"
+	if gotQuery != want {
+		t.Fatalf("query %s, want %s", gotQuery, want)
+	}
+
+	if len(ids.ItemRefs) != 2 || ids.ItemRefs[0].DirectStreamIds[0] != "feed/http://a/" || ids.Continuation != "page2" {
+		t.Fatalf("decoded %#v", ids)
+	}
+
+	if id, err := ids.ItemRefs[1].ItemID(); err != nil || id.Long() != "tag:google.com,2005:reader/item/0000000000000002" {
+		t.Fatalf("ItemID() = %v, %v", id, err)
+	}
+
+	it := NewItemRefIterator(context.Background(), rc, &ItemIDsOptions{Count: 2}, 0)
+
+	var got []string
+	for it.Next() {
+		got = append(got, it.ItemRef().ID)
+	}
+	if err := it.Err(); err != nil {
+		t.Fatal(err)
+	}
+
+	if fmt.Sprint(got) != "[1 2 3]" {
+		t.Fatalf("iterated %v, want [1 2 3]", got)
+	}
+}
+
+func TestItemRefIteratorCancel(t *testing.T) {
+	var requests int
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"itemRefs": [{"id": "1"}, {"id": "2"}, {"id": "3"}, {"id": "4"}], "continuation": "next"}`))
+	}))
+	defer srv.Close()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	it := NewItemRefIterator(ctx, resty.New().SetHostURL(srv.URL), nil, 0)
+
+	n := 0
+	for it.Next() {
+		n++
+		if n == 2 {
+			cancel()
+		}
+	}
+
+	if !errors.Is(it.Err(), context.Canceled) || n != 2 || requests != 1 {
+		t.Fatalf("stopped after %d references and %d requests with %v, want 2, 1 and %v", n, requests, it.Err(), context.Canceled)
+	}
+}
+
+func TestItemIDsOptionsValidate(t *testing.T) {
+	if err := (&ItemIDsOptions{Count: MaxItemIDsCount}).Validate(); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := (&ItemIDsOptions{Count: MaxItemIDsCount + 1}).Validate(); err == nil {
+		t.Fatalf("Count %d accepted", MaxItemIDsCount+1)
+	}
+}
@@ -0,0 +1,222 @@
+package stream
+
+import (
+	"context"
+	"net/url"
+	"strconv"
+	"time"
+
+	"github.com/go-resty/resty/v2"
+	"github.com/hyperreal64/go-inoreader/api"
+	"github.com/pkg/errors"
+)
+
+// Largest number of entries the API returns per request. stream/contents
+// returns at most MaxContentsCount items, and stream/items/ids at most
+// MaxItemIDsCount references; larger counts are clamped by the server.
+const (
+	MaxContentsCount = 100
+	MaxItemIDsCount  = 1000
+)
+
+// Number of items the API returns when no count is given.
+const defaultCount = 20
+
+// Order is the order items of a stream are returned in.
+type Order int
+
+const (
+	// OrderNewestFirst is the API's default order.
+	OrderNewestFirst Order = iota
+	OrderOldestFirst
+)
+
+func (o Order) String() string {
+
+	switch o {
+	case OrderNewestFirst:
+		return "newest-first"
+	case OrderOldestFirst:
+		return "oldest-first"
+	default:
+		return "order" + strconv.Itoa(int(o))
+	}
+}
+
+// StreamContentsOptions are the parameters of a stream/contents request.
+// The zero value asks for the API's default page of the reading list.
+type StreamContentsOptions struct {
+	// StreamID is the feed, label or state to read, such as
+	// Feed("https://fedoramagazine.org/feed/"). Empty means the reading list.
+	StreamID StreamID
+
+	// Count is the number of items to return, up to MaxContentsCount. Zero
+	// leaves the API default of 20.
+	Count int
+
+	Order Order
+
+	// NewerThan and OlderThan bound the items' crawl time. Zero values
+	// leave the stream unbounded on that side.
+	//
+	// The API only takes the lower bound (`ot`). OlderThan is applied to
+	// each page after it arrives, so a page may hold fewer than Count
+	// items; with OrderOldestFirst, the stream ends at the first item past
+	// the bound, while newest-first pages are still fetched until the
+	// bound is reached.
+	NewerThan time.Time
+	OlderThan time.Time
+
+	// Include and Exclude keep or drop the items in any of the given
+	// streams, typically states such as State(Read).
+	Include []StreamID
+	Exclude []StreamID
+
+	// Continuation is the token from the previous page's response.
+	Continuation string
+
+	// Annotations asks for the items' annotations.
+	Annotations bool
+}
+
+// Validate reports the first invalid field of o, if any.
+func (o *StreamContentsOptions) Validate() error {
+
+	if err := validateCount(o.Count, MaxContentsCount); err != nil {
+		return err
+	}
+
+	if !o.NewerThan.IsZero() && !o.OlderThan.IsZero() && !o.NewerThan.Before(o.OlderThan) {
+		return errors.Errorf("Invalid time range: NewerThan %s is not before OlderThan %s",
+			o.NewerThan.Format(time.RFC3339), o.OlderThan.Format(time.RFC3339))
+	}
+
+	if o.Order != OrderNewestFirst && o.Order != OrderOldestFirst {
+		return errors.Errorf("Invalid order %s", o.Order)
+	}
+
+	for _, id := range append(append([]StreamID{}, o.Include...), o.Exclude...) {
+		if id == "" {
+			return errors.New("Invalid stream filter: empty stream ID")
+		}
+	}
+
+	return nil
+}
+
+// Reports whether `count` is between 0 and `max`.
+func validateCount(count, max int) error {
+
+	if count < 0 || count > max {
+		return errors.Errorf("Invalid count %d: must be between 0 and %d", count, max)
+	}
+
+	return nil
+}
+
+// Encode validates o and returns its query parameters. Include and Exclude
+// become one `it` or `xt` parameter per stream ID. OlderThan has no
+// parameter; it is applied to the response instead.
+func (o *StreamContentsOptions) Encode() (url.Values, error) {
+
+	if err := o.Validate(); err != nil {
+		return nil, err
+	}
+
+	v := url.Values{}
+	if o.Count > 0 {
+		v.Set("n", strconv.Itoa(o.Count))
+	}
+
+	if o.Order == OrderOldestFirst {
+		v.Set("r", "o")
+	}
+
+	if !o.NewerThan.IsZero() {
+		v.Set("ot", strconv.FormatInt(o.NewerThan.Unix(), 10))
+	}
+
+	for _, id := range o.Include {
+		v.Add("it", string(id))
+	}
+
+	for _, id := range o.Exclude {
+		v.Add("xt", string(id))
+	}
+
+	if o.Continuation != "" {
+		v.Set("c", o.Continuation)
+	}
+
+	if o.Annotations {
+		v.Set("annotations", "1")
+	}
+
+	return v, nil
+}
+
+// Returns the endpoint path for `base` with `streamID` appended, if any.
+func streamPath(base string, streamID StreamID) string {
+
+	if streamID == "" {
+		return base
+	}
+
+	return base + "/" + url.PathEscape(string(streamID))
+}
+
+// Gets the contents of the stream described by opts. A nil opts is the
+// same as the zero StreamContentsOptions.
+func GetStreamContentsWithOptions(ctx context.Context, rc *resty.Client, opts *StreamContentsOptions) (sc *StreamContents, err error) {
+
+	if opts == nil {
+		opts = &StreamContentsOptions{}
+	}
+
+	params, err := opts.Encode()
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := rc.R().
+		SetContext(ctx).
+		SetQueryParamsFromValues(params).
+		Get(api.Endpoint(rc, streamPath(streamContentsURL, opts.StreamID)))
+	if err := api.CheckResponseContext(ctx, resp, err); err != nil {
+		return nil, err
+	}
+
+	if err := resty.Unmarshalc(rc, "application/json", resp.Body(), &sc); err != nil {
+		return nil, errors.Wrap(err, "Unable to unmarshal stream contents")
+	}
+
+	if !opts.OlderThan.IsZero() {
+		kept := sc.Items[:0]
+		for _, item := range sc.Items {
+			if beforeBound(item.TimestampUsec.Time, opts.OlderThan) {
+				kept = append(kept, item)
+			}
+		}
+		sc.Continuation = boundedContinuation(sc.Continuation, opts.Order, len(kept) < len(sc.Items))
+		sc.Items = kept
+	}
+
+	return sc, nil
+}
+
+// Reports whether an entry stamped `ts` is before the OlderThan bound
+// `bound`. Entries without a timestamp are kept.
+func beforeBound(ts, bound time.Time) bool {
+	return ts.IsZero() || ts.Before(bound)
+}
+
+// Returns the continuation of a page filtered by OlderThan: oldest first,
+// nothing after a dropped entry can be within the bound, so the stream ends.
+func boundedContinuation(continuation string, order Order, dropped bool) string {
+
+	if dropped && order == OrderOldestFirst {
+		return ""
+	}
+
+	return continuation
+}
@@ -0,0 +1,138 @@
+package stream
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/go-resty/resty/v2"
+)
+
+func TestStreamContentsOptionsEncode(t *testing.T) {
+	opts := &StreamContentsOptions{
+		Count:        50,
+		Order:        OrderOldestFirst,
+		NewerThan:    time.Unix(1614556800, 0),
+		OlderThan:    time.Unix(1614643200, 0),
+		Include:      []StreamID{"user/-/state/com.google/starred"},
+		Exclude:      []StreamID{"user/-/state/com.google/read", "user/-/label/Muted"},
+		Continuation: "abc",
+		Annotations:  true,
+	}
+
+	v, err := opts.Encode()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := map[string][]string{
+		"n":           {"50"},
+		"r":           {"o"},
+		"ot":          {"1614556800"},
+		"it":          {"user/-/state/com.google/starred"},
+		"xt":          {"user/-/state/com.google/read", "user/-/label/Muted"},
+		"c":           {"abc"},
+		"annotations": {"1"},
+	}
+	if !reflect.DeepEqual(map[string][]string(v), want) {
+		t.Fatalf("Encode() = %v, want %v", v, want)
+	}
+
+	if v, err := (&StreamContentsOptions{}).Encode(); err != nil || len(v) != 0 {
+		t.Fatalf("zero options encode to %v, %v, want no parameters", v, err)
+	}
+}
+
+func TestStreamContentsOptionsValidate(t *testing.T) {
+	invalid := []*StreamContentsOptions{
+		{Count: -1},
+		{Count: 101},
+		{Order: Order(7)},
+		{NewerThan: time.Unix(200, 0), OlderThan: time.Unix(100, 0)},
+		{Exclude: []StreamID{""}},
+	}
+
+	for _, opts := range invalid {
+		if err := opts.Validate(); err == nil {
+			t.Fatalf("Validate(%#v) = nil, want an error", opts)
+		}
+	}
+}
+
+func TestGetStreamContentsWithOptions(t *testing.T) {
+	var gotURI string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotURI = r.RequestURI
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"id": "feed/https://fedoramagazine.org/feed/", "items": [{"id": "tag:google.com,2005:reader/item/0000000000000001"}], "continuation": "next"}`))
+	}))
+	defer srv.Close()
+
+	rc := resty.New().SetHostURL(srv.URL)
+	sc, err := GetStreamContentsWithOptions(context.Background(), rc, &StreamContentsOptions{
+		StreamID: "feed/https://fedoramagazine.org/feed/",
+		Count:    5,
+		Exclude:  []StreamID{"user/-/state/com.google/read", "user/-/label/Muted"},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := "/stream/contents/feed%2Fhttps:%2F%2Ffedoramagazine.org%2Ffeed%2F?n=5&xt=user%2F-%2Fstate%2Fcom.google%2Fread&xt=user%2F-%2Flabel%2FMuted"
+	if gotURI != want {
+		t.Fatalf("requested %s, want %s", gotURI, want)
+	}
+
+	if len(sc.Items) != 1 || sc.Continuation != "next" {
+		t.Fatalf("decoded %#v", sc)
+	}
+
+	if _, err := GetStreamContentsWithOptions(context.Background(), rc, &StreamContentsOptions{Count: -1}); err == nil {
+		t.Fatal("invalid options were sent")
+	}
+}
+
+func TestOlderThan(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.FormValue("nt") != "" {
+			t.Errorf("sent nt=%s", r.FormValue("nt"))
+		}
+
+		entries := `{"id": "1", "timestampUsec": "100000000"}, {"id": "2", "timestampUsec": "200000000"}, {"id": "3", "timestampUsec": "300000000"}`
+		w.Header().Set("Content-Type", "application/json")
+		fmt.Fprintf(w, `{"items": [%s], "itemRefs": [%s], "continuation": "next"}`, entries, entries)
+	}))
+	defer srv.Close()
+
+	rc := resty.New().SetHostURL(srv.URL)
+	bound := time.Unix(250, 0)
+
+	for _, order := range []Order{OrderNewestFirst, OrderOldestFirst} {
+		want := "next"
+		if order == OrderOldestFirst {
+			want = ""
+		}
+
+		sc, err := GetStreamContentsWithOptions(context.Background(), rc, &StreamContentsOptions{Order: order, OlderThan: bound})
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if len(sc.Items) != 2 || sc.Items[1].ID != "2" || sc.Continuation != want {
+			t.Fatalf("%s: kept %d items, continuation %q; want 2 and %q", order, len(sc.Items), sc.Continuation, want)
+		}
+
+		ids, err := GetItemIDs(context.Background(), rc, &ItemIDsOptions{Order: order, OlderThan: bound})
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if len(ids.ItemRefs) != 2 || ids.ItemRefs[1].ID != "2" || ids.Continuation != want {
+			t.Fatalf("%s: kept %d references, continuation %q; want 2 and %q", order, len(ids.ItemRefs), ids.Continuation, want)
+		}
+	}
+}
@@ -0,0 +1,182 @@
+package stream
+
+import (
+	"context"
+
+	"github.com/go-resty/resty/v2"
+)
+
+// PageFunc fetches the page that starts at `continuation`, which is empty
+// for the first page, and returns the continuation token of the page after
+// it, or "" if it was the last one. It keeps the page's entries itself,
+// typically in a buffer of the iterator that created it.
+type PageFunc func(ctx context.Context, continuation string) (next string, err error)
+
+// Pager follows the continuation tokens of a paged endpoint such as
+// stream/contents or stream/items/ids, fetching one page per call to Next.
+type Pager struct {
+	fetch        PageFunc
+	continuation string
+	exhausted    bool
+	err          error
+}
+
+// NewPager returns a Pager whose first page starts at `continuation`, or at
+// the start of the stream if it is empty.
+func NewPager(continuation string, fetch PageFunc) *Pager {
+	return &Pager{fetch: fetch, continuation: continuation}
+}
+
+// Next fetches the next page. It returns false once the last page has been
+// fetched, or on error; Err tells the two apart. After an error,
+// Continuation still returns the token of the page that failed.
+func (p *Pager) Next(ctx context.Context) bool {
+
+	if p.exhausted || p.err != nil {
+		return false
+	}
+
+	if err := ctx.Err(); err != nil {
+		p.err = err
+		return false
+	}
+
+	next, err := p.fetch(ctx, p.continuation)
+	if err != nil {
+		p.err = err
+		return false
+	}
+
+	// A token that does not move would fetch the same page forever.
+	if next == "" || next == p.continuation {
+		p.exhausted = true
+		next = ""
+	}
+	p.continuation = next
+
+	return true
+}
+
+// Err returns the error that stopped the Pager, if any.
+func (p *Pager) Err() error {
+	return p.err
+}
+
+// Continuation returns the token of the next page to fetch, which can be
+// saved to resume later, also after a failed page. It is empty once the
+// stream is exhausted.
+func (p *Pager) Continuation() string {
+	return p.continuation
+}
+
+// ItemIterator yields the items of a stream one by one, fetching pages of
+// stream/contents as needed. Stopping early is just not calling Next again.
+//
+//	it := stream.NewItemIterator(ctx, rc, opts, 500)
+//	for it.Next() {
+//		item := it.Item()
+//		...
+//	}
+//	if err := it.Err(); err != nil {
+//		...
+//	}
+type ItemIterator struct {
+	ctx   context.Context
+	pager *Pager
+	max   int
+	n     int
+	buf   []Item
+	cur   Item
+	err   error
+}
+
+// NewItemIterator returns an iterator over the items described by opts,
+// starting at opts.Continuation. opts.Count sets the page size. `max` caps
+// the total number of items yielded; 0 means no cap.
+func NewItemIterator(ctx context.Context, rc *resty.Client, opts *StreamContentsOptions, max int) *ItemIterator {
+
+	o := StreamContentsOptions{}
+	if opts != nil {
+		o = *opts
+	}
+
+	it := &ItemIterator{ctx: ctx, max: max}
+	it.pager = NewPager(o.Continuation, func(ctx context.Context, continuation string) (string, error) {
+
+		page := o
+		page.Continuation = continuation
+		page.Count = pageSize(o.Count, it.max, it.n)
+
+		sc, err := GetStreamContentsWithOptions(ctx, rc, &page)
+		if err != nil {
+			return "", err
+		}
+
+		it.buf = sc.Items
+		return sc.Continuation, nil
+	})
+
+	return it
+}
+
+// Returns the page size to request when `seen` of at most `max` entries
+// have been yielded and `count` is the requested size.
+func pageSize(count, max, seen int) int {
+
+	if max <= 0 {
+		return count
+	}
+
+	size := count
+	if size == 0 {
+		size = defaultCount
+	}
+
+	if remaining := max - seen; remaining < size {
+		return remaining
+	}
+
+	return count
+}
+
+// Next advances to the next item. It returns false when the stream is
+// exhausted, the maximum count was reached, the context is done or a request
+// failed.
+func (it *ItemIterator) Next() bool {
+
+	if err := it.ctx.Err(); err != nil {
+		it.err = err
+		return false
+	}
+
+	if it.max > 0 && it.n >= it.max {
+		return false
+	}
+
+	for len(it.buf) == 0 {
+		if !it.pager.Next(it.ctx) {
+			return false
+		}
+	}
+
+	it.cur = it.buf[0]
+	it.buf = it.buf[1:]
+	it.n++
+
+	return true
+}
+
+// Item returns the current item.
+func (it *ItemIterator) Item() Item {
+	return it.cur
+}
+
+// Err returns the error that stopped the iterator, if any.
+func (it *ItemIterator) Err() error {
+
+	if it.err != nil {
+		return it.err
+	}
+
+	return it.pager.Err()
+}
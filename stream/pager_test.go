@@ -0,0 +1,150 @@
+package stream
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/go-resty/resty/v2"
+	"github.com/pkg/errors"
+)
+
+// Returns a server paging through `total` items, and the page sizes it was
+// asked for.
+func pagedServer(t *testing.T, total int) (*httptest.Server, *[]string) {
+
+	var counts []string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		counts = append(counts, r.FormValue("n"))
+
+		n, _ := strconv.Atoi(r.FormValue("n"))
+		if n == 0 {
+			n = defaultCount
+		}
+		start, _ := strconv.Atoi(r.FormValue("c"))
+
+		var items []string
+		for i := start; i < start+n && i < total; i++ {
+			items = append(items, fmt.Sprintf(`{"id": "%d"}`, i))
+		}
+
+		continuation := ""
+		if start+n < total {
+			continuation = strconv.Itoa(start + n)
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		fmt.Fprintf(w, `{"items": [%s], "continuation": "%s"}`, strings.Join(items, ","), continuation)
+	}))
+	t.Cleanup(srv.Close)
+
+	return srv, &counts
+}
+
+func TestItemIterator(t *testing.T) {
+	srv, counts := pagedServer(t, 25)
+	rc := resty.New().SetHostURL(srv.URL)
+
+	it := NewItemIterator(context.Background(), rc, &StreamContentsOptions{Count: 10}, 0)
+
+	var ids []string
+	for it.Next() {
+		ids = append(ids, it.Item().ID)
+	}
+	if err := it.Err(); err != nil {
+		t.Fatal(err)
+	}
+
+	if len(ids) != 25 || ids[0] != "0" || ids[24] != "24" || len(*counts) != 3 {
+		t.Fatalf("got %d items in %d requests, want 25 in 3", len(ids), len(*counts))
+	}
+}
+
+func TestItemIteratorMax(t *testing.T) {
+	srv, counts := pagedServer(t, 100)
+	rc := resty.New().SetHostURL(srv.URL)
+
+	it := NewItemIterator(context.Background(), rc, &StreamContentsOptions{Count: 10}, 15)
+
+	n := 0
+	for it.Next() {
+		n++
+	}
+
+	if n != 15 || fmt.Sprint(*counts) != "[10 5]" {
+		t.Fatalf("got %d items with page sizes %v, want 15 with [10 5]", n, *counts)
+	}
+}
+
+func TestItemIteratorCancel(t *testing.T) {
+	srv, counts := pagedServer(t, 100)
+	rc := resty.New().SetHostURL(srv.URL)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	it := NewItemIterator(ctx, rc, nil, 0)
+
+	n := 0
+	for it.Next() {
+		n++
+		if n == 5 {
+			cancel()
+		}
+	}
+
+	if !errors.Is(it.Err(), context.Canceled) || n != 5 || len(*counts) != 1 {
+		t.Fatalf("stopped after %d items and %d requests with %v, want 5, 1 and %v", n, len(*counts), it.Err(), context.Canceled)
+	}
+}
+
+func TestPagerRepeatedToken(t *testing.T) {
+	calls := 0
+	p := NewPager("", func(ctx context.Context, continuation string) (string, error) {
+		calls++
+		return "same", nil
+	})
+
+	for p.Next(context.Background()) {
+	}
+
+	if calls != 2 || p.Err() != nil || p.Continuation() != "" {
+		t.Fatalf("%d calls, err %v, continuation %q; want 2, nil, empty", calls, p.Err(), p.Continuation())
+	}
+}
+
+func TestPagerResume(t *testing.T) {
+	fail := true
+	var fetched []string
+	fetch := func(ctx context.Context, continuation string) (string, error) {
+		if continuation == "page2" && fail {
+			return "", errors.New("Service Unavailable")
+		}
+		fetched = append(fetched, continuation)
+
+		if continuation == "" {
+			return "page2", nil
+		}
+		return "", nil
+	}
+
+	p := NewPager("", fetch)
+	for p.Next(context.Background()) {
+	}
+
+	if p.Err() == nil || p.Continuation() != "page2" {
+		t.Fatalf("err %v, continuation %q; want an error and page2", p.Err(), p.Continuation())
+	}
+
+	fail = false
+	p = NewPager(p.Continuation(), fetch)
+	for p.Next(context.Background()) {
+	}
+
+	if p.Err() != nil || p.Continuation() != "" || fmt.Sprint(fetched) != "[ page2]" {
+		t.Fatalf("fetched %q, err %v, continuation %q after resuming", fetched, p.Err(), p.Continuation())
+	}
+}
@@ -0,0 +1,128 @@
+package stream
+
+import (
+	"context"
+	"strconv"
+	"strings"
+
+	"github.com/go-resty/resty/v2"
+	"github.com/hyperreal64/go-inoreader/api"
+	"github.com/pkg/errors"
+)
+
+// Stream preference keys known to the API.
+const (
+	// PrefSubscriptionOrdering holds the order of the subscriptions and
+	// folders inside a folder, or inside RootStreamID for the top level,
+	// as concatenated sortids.
+	PrefSubscriptionOrdering = "subscription-ordering"
+)
+
+// RootStreamID is the stream holding the top-level subscription ordering.
+const RootStreamID StreamID = "user/-/state/com.google/root"
+
+// Length of a sortid, as in the Sortid fields of subscriptions and tags.
+const sortIDLen = 8
+
+// Preferences returns the preferences of `streamID` as a key/value map, or
+// nil if it has none. User streams match with or without the numeric user
+// ID.
+func (l *StreamPreferenceList) Preferences(streamID StreamID) map[string]string {
+
+	for id, prefs := range l.Streamprefs {
+		if !id.Equal(streamID) {
+			continue
+		}
+
+		m := make(map[string]string, len(prefs))
+		for _, p := range prefs {
+			m[p.ID] = p.Value
+		}
+		return m
+	}
+
+	return nil
+}
+
+// Get returns the value of preference `key` of `streamID`.
+func (l *StreamPreferenceList) Get(streamID StreamID, key string) (value string, ok bool) {
+	value, ok = l.Preferences(streamID)[key]
+	return value, ok
+}
+
+// SubscriptionOrdering returns the sortids of the subscriptions and folders
+// in `streamID`, in the user's order. Use RootStreamID for the top level.
+func (l *StreamPreferenceList) SubscriptionOrdering(streamID StreamID) ([]string, error) {
+
+	value, ok := l.Get(streamID, PrefSubscriptionOrdering)
+	if !ok {
+		return nil, nil
+	}
+
+	return DecodeSortIDs(value)
+}
+
+// DecodeSortIDs splits a subscription-ordering value into its sortids,
+// which are 8 hexadecimal digits each.
+func DecodeSortIDs(value string) ([]string, error) {
+
+	if len(value)%sortIDLen != 0 {
+		return nil, errors.Errorf("Invalid subscription ordering: length %d is not a multiple of %d", len(value), sortIDLen)
+	}
+
+	ids := make([]string, 0, len(value)/sortIDLen)
+	for i := 0; i < len(value); i += sortIDLen {
+		id := value[i : i+sortIDLen]
+		if _, err := strconv.ParseUint(id, 16, 32); err != nil {
+			return nil, errors.Errorf("Invalid subscription ordering: %q at offset %d is not a sortid", id, i)
+		}
+		ids = append(ids, id)
+	}
+
+	return ids, nil
+}
+
+// EncodeSortIDs joins sortids into a subscription-ordering value.
+func EncodeSortIDs(ids []string) (string, error) {
+
+	for _, id := range ids {
+		if len(id) != sortIDLen {
+			return "", errors.Errorf("Invalid sortid %q: must be %d characters", id, sortIDLen)
+		}
+	}
+
+	return strings.Join(ids, ""), nil
+}
+
+// Gets the preferences of every stream that has some.
+func GetStreamPreferences(ctx context.Context, rc *resty.Client) (prefs *StreamPreferenceList, err error) {
+
+	resp, err := rc.R().SetContext(ctx).Get(api.Endpoint(rc, streamPrefsURL))
+	if err := api.CheckResponseContext(ctx, resp, err); err != nil {
+		return nil, err
+	}
+
+	if err := resty.Unmarshalc(rc, "application/json", resp.Body(), &prefs); err != nil {
+		return nil, errors.Wrap(err, "Unable to unmarshal stream preferences")
+	}
+
+	return prefs, nil
+}
+
+// Sets preference `key` of `streamID` to `value`. Sends a POST request.
+func SetStreamPreference(ctx context.Context, rc *resty.Client, streamID StreamID, key, value string) error {
+
+	resp, err := rc.R().
+		SetContext(ctx).
+		SetQueryParams(map[string]string{
+			"s": string(streamID),
+			"k": key,
+			"v": value,
+		}).
+		Post(api.Endpoint(rc, streamPrefsSetURL))
+	if err := api.CheckResponseContext(ctx, resp, err); err != nil {
+		return err
+	}
+
+	return nil
+}
@@ -0,0 +1,82 @@
+package stream
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/go-resty/resty/v2"
+)
+
+func TestStreamPreferences(t *testing.T) {
+	var setQuery string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodPost {
+			setQuery = r.URL.RawQuery
+			w.Write([]byte("OK"))
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"streamprefs": {
+			"user/1005869311/state/com.google/root": [{"id": "subscription-ordering", "value": "0000000100000002FFFFFFFF"}],
+			"user/1005869311/label/Tech": [{"id": "subscription-ordering", "value": ""}, {"id": "is-expanded", "value": "false"}]
+		}}`))
+	}))
+	defer srv.Close()
+
+	rc := resty.New().SetHostURL(srv.URL)
+	prefs, err := GetStreamPreferences(context.Background(), rc)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	order, err := prefs.SubscriptionOrdering(RootStreamID)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if fmt.Sprint(order) != "[00000001 00000002 FFFFFFFF]" {
+		t.Fatalf("root ordering %v", order)
+	}
+
+	if v, ok := prefs.Get("user/-/label/Tech", "is-expanded"); !ok || v != "false" {
+		t.Fatalf("is-expanded = %q, %v", v, ok)
+	}
+
+	if p := prefs.Preferences("user/-/label/Missing"); p != nil {
+		t.Fatalf("preferences of a missing stream: %v", p)
+	}
+
+	value, err := EncodeSortIDs(order[1:])
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := SetStreamPreference(context.Background(), rc, RootStreamID, PrefSubscriptionOrdering, value); err != nil {
+		t.Fatal(err)
+	}
+
+	want := "k=subscription-ordering&s=user%2F-%2Fstate%2Fcom.google%2Froot&v=00000002FFFFFFFF"
+	if setQuery != want {
+		t.Fatalf("set query %s, want %s", setQuery, want)
+	}
+}
+
+func TestDecodeSortIDs(t *testing.T) {
+	for _, value := range []string{"0000000", "0000000100000zzz"} {
+		if _, err := DecodeSortIDs(value); err == nil {
+			t.Fatalf("DecodeSortIDs(%q) succeeded", value)
+		}
+	}
+
+	if ids, err := DecodeSortIDs(""); err != nil || len(ids) != 0 {
+		t.Fatalf("DecodeSortIDs(\"\") = %v, %v", ids, err)
+	}
+
+	if _, err := EncodeSortIDs([]string{"123"}); err == nil {
+		t.Fatal("EncodeSortIDs accepted a short sortid")
+	}
+}
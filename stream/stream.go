@@ -1,99 +1,128 @@
 package stream
 
 import (
+	"context"
+	"strconv"
+	"time"
+
 	"github.com/go-resty/resty/v2"
+	"github.com/hyperreal64/go-inoreader/api"
 )
 
-// API URLs for resty.Client requests
+// API endpoint paths, relative to api.DefaultBaseURL
 const (
-	streamContentsURL = "https://www.inoreader.com/reader/api/0/stream/contents"
-	itemIDsURL        = "https://www.inoreader.com/reader/api/0/stream/items/ids"
-	streamPrefsURL    = "https://www.inoreader.com/reader/api/0/preference/stream/list"
-	streamPrefsSetURL = "https://www.inoreader.com/reader/api/0/preference/stream/set"
-	markAllReadURL    = "https://www.inoreader.com/reader/api/0/mark-all-as-read"
+	streamContentsURL = "stream/contents"
+	itemIDsURL        = "stream/items/ids"
+	itemContentsURL   = "stream/items/contents"
+	streamPrefsURL    = "preference/stream/list"
+	streamPrefsSetURL = "preference/stream/set"
+	markAllReadURL    = "mark-all-as-read"
 )
 
 // StreamContents JSON response
 type StreamContents struct {
-	Direction   string `json:"direction"`
-	ID          string `json:"id"`
-	Title       string `json:"title"`
-	Description string `json:"description"`
-	Updated     int    `json:"updated"`
-	UpdatedUsec string `json:"updatedUsec"`
-	Self        struct {
-		Href string `json:"href"`
-	} `json:"self"`
-	Items        []Item `json:"items"`
-	Continuation string `json:"continuation"`
+	Direction    string       `json:"direction"`
+	ID           StreamID     `json:"id"`
+	Title        string       `json:"title"`
+	Description  string       `json:"description"`
+	Updated      api.UnixTime `json:"updated"`
+	UpdatedUsec  api.UsecTime `json:"updatedUsec"`
+	Self         Link         `json:"self"`
+	Items        []Item       `json:"items"`
+	Continuation string       `json:"continuation"`
 }
 
 // Item JSON response
 type Item struct {
-	CrawlTimeMsec string   `json:"crawlTimeMsec"`
-	TimestampUsec string   `json:"timestampUsec"`
-	ID            string   `json:"id"`
-	Categories    []string `json:"categories"`
-	Title         string   `json:"title"`
-	Published     int64    `json:"published"`
-	Updated       int      `json:"updated"`
-	Canonical     []struct {
-		Href string `json:"href"`
-	} `json:"canonical"`
-	Author      string        `json:"author"`
-	LikingUsers []interface{} `json:"likingUsers"`
-	Comments    []interface{} `json:"comments"`
-	CommentsNum int           `json:"commentsNum"`
-	Annotations []interface{} `json:"annotations"`
-	Origin      *Origin       `json:"origin"`
+	CrawlTimeMsec api.MsecTime `json:"crawlTimeMsec"`
+	TimestampUsec api.UsecTime `json:"timestampUsec"`
+	ID            string       `json:"id"`
+	Categories    []StreamID   `json:"categories"`
+	Title         string       `json:"title"`
+	Published     api.UnixTime `json:"published"`
+	Updated       api.UnixTime `json:"updated"`
+	Canonical     []Link       `json:"canonical"`
+	Alternate     []Link       `json:"alternate"`
+	Summary       Content      `json:"summary"`
+	Content       *Content     `json:"content,omitempty"`
+	Enclosure     []Enclosure  `json:"enclosure,omitempty"`
+	Visual        *Visual      `json:"visual,omitempty"`
+	Author        string       `json:"author"`
+	LikingUsers   []LikingUser `json:"likingUsers"`
+	Comments      []Comment    `json:"comments"`
+	CommentsNum   api.FlexInt  `json:"commentsNum"`
+	Annotations   []Annotation `json:"annotations"`
+	Origin        *Origin      `json:"origin"`
 }
 
 // Origin JSON response
 type Origin struct {
-	StreamID string `json:"streamId"`
-	Title    string `json:"title"`
-	HTMLURL  string `json:"htmlUrl"`
+	StreamID StreamID `json:"streamId"`
+	Title    string   `json:"title"`
+	HTMLURL  string   `json:"htmlUrl"`
 }
 
 // ItemIDs JSON response
 type ItemIDs struct {
 	Items        []interface{} `json:"items"`
-	ItemRefs     []interface{} `json:"itemRefs"`
+	ItemRefs     []ItemRef     `json:"itemRefs"`
 	Continuation string        `json:"continuation"`
 }
 
-// ItemRefs JSON response
-type ItemRefs struct {
-	ID              string        `json:"id"`
-	DirectStreamIds []interface{} `json:"directStreamIds"`
-	TimestampUsec   string        `json:"timestampUsec"`
+// ItemRef JSON response. ID is the short, decimal form of the item ID.
+type ItemRef struct {
+	ID              string       `json:"id"`
+	DirectStreamIds []StreamID   `json:"directStreamIds"`
+	TimestampUsec   api.UsecTime `json:"timestampUsec"`
 }
 
-// StreamPreferenceList JSON response
+// ItemRefs is the former name of ItemRef.
+//
+// Deprecated: use ItemRef.
+type ItemRefs = ItemRef
+
+// StreamPreferenceList JSON response. Streamprefs maps stream IDs to their
+// preferences; the API writes user streams with the numeric user ID, such
+// as "user/1005869311/state/com.google/root".
 type StreamPreferenceList struct {
-	Streamprefs interface{} `json:"streamprefs"`
+	Streamprefs map[StreamID][]StreamPreference `json:"streamprefs"`
+}
+
+// StreamPreference JSON response; one key/value preference of a stream.
+type StreamPreference struct {
+	ID    string `json:"id"`
+	Value string `json:"value"`
 }
 
 // Streamprefs JSON response
+//
+// Deprecated: StreamPreferenceList.Streamprefs is now a map of every
+// stream's preferences.
 type Streamprefs struct {
-	UserStateComGoogleRoot []interface{} `json:"user/-/state/com.google/root"`
+	UserStateComGoogleRoot []StreamPreference `json:"user/-/state/com.google/root"`
 }
 
-// UserStateComGoogleRoot JSON response
-type UserStateComGoogleRoot struct {
-	ID    string `json:"id"`
-	Value string `json:"value"`
-}
+// UserStateComGoogleRoot is the former name of StreamPreference.
+//
+// Deprecated: use StreamPreference.
+type UserStateComGoogleRoot = StreamPreference
 
 // Gets stream contents based on set query parameters and returns a struct
-// containing StreamContents JSON response
+// containing StreamContents JSON response. GetStreamContentsWithOptions
+// takes a StreamID and typed options instead.
 func GetStreamContents(rc *resty.Client, params map[string]string) (sc *StreamContents, err error) {
+	return GetStreamContentsContext(context.Background(), rc, params)
+}
+
+// Same as GetStreamContents, but the request is bound to ctx.
+func GetStreamContentsContext(ctx context.Context, rc *resty.Client, params map[string]string) (sc *StreamContents, err error) {
 
 	resp, err := rc.R().
+		SetContext(ctx).
 		SetQueryParams(params).
-		Get(streamContentsURL)
+		Get(api.Endpoint(rc, streamContentsURL))
 
-	if err != nil {
+	if err := api.CheckResponseContext(ctx, resp, err); err != nil {
 		return nil, err
 	}
 
@@ -104,15 +133,34 @@ func GetStreamContents(rc *resty.Client, params map[string]string) (sc *StreamCo
 	return sc, nil
 }
 
-// Marks all items in stream as read; stream is specified in query parameters
+// Marks all items in stream as read; stream is specified in query parameters.
+// MarkStreamAsRead takes the StreamID instead.
 func MarkAllAsRead(rc *resty.Client, params map[string]string) error {
+	return MarkAllAsReadContext(context.Background(), rc, params)
+}
+
+// Same as MarkAllAsRead, but the request is bound to ctx.
+func MarkAllAsReadContext(ctx context.Context, rc *resty.Client, params map[string]string) error {
 
-	_, err := rc.R().
+	resp, err := rc.R().
+		SetContext(ctx).
 		SetQueryParams(params).
-		Post(markAllReadURL)
-	if err != nil {
+		Post(api.Endpoint(rc, markAllReadURL))
+	if err := api.CheckResponseContext(ctx, resp, err); err != nil {
 		return err
 	}
 
 	return nil
 }
+
+// Marks the items of `streamID` as read, or only those older than `before`
+// if it is not zero. Sends a POST request.
+func MarkStreamAsRead(ctx context.Context, rc *resty.Client, streamID StreamID, before time.Time) error {
+
+	params := map[string]string{"s": string(streamID)}
+	if !before.IsZero() {
+		params["ts"] = strconv.FormatInt(api.UsecTime{Time: before}.Usec(), 10)
+	}
+
+	return MarkAllAsReadContext(ctx, rc, params)
+}
@@ -15,7 +15,7 @@ func TestStreamContents(t *testing.T) {
 	params := map[string]string{
 		"n": "5",
 		"r": "n",
-		"s": "feed/https://fedoramagazine.org/feed/",
+		"s": Feed("https://fedoramagazine.org/feed/").String(),
 	}
 
 	sc, err := GetStreamContents(rc, params)
@@ -33,7 +33,7 @@ func TestMarkAllAsRead(t *testing.T) {
 
 	feedURL := "https://fedoramagazine.org/feed/"
 	params := map[string]string{
-		"s": "feed/" + feedURL,
+		"s": Feed(feedURL).String(),
 	}
 
 	if err := MarkAllAsRead(rc, params); err != nil {
@@ -0,0 +1,309 @@
+package stream
+
+import (
+	"context"
+	"strings"
+	"sync"
+
+	"github.com/go-resty/resty/v2"
+	"github.com/hyperreal64/go-inoreader/userinfo"
+	"github.com/pkg/errors"
+)
+
+// StreamID identifies a stream: a feed ("feed/<url>"), a label or folder
+// ("user/-/label/<name>"), an active search ("user/-/active_search/<name>")
+// or a state ("user/-/state/com.google/<state>").
+// In user streams, "-" stands for the current user; the API also writes the
+// numeric user ID in its place.
+type StreamID string
+
+// StreamKind is the kind of stream a StreamID names.
+type StreamKind int
+
+const (
+	KindUnknown StreamKind = iota
+	KindFeed
+	KindLabel
+	KindState
+	KindActiveSearch
+)
+
+func (k StreamKind) String() string {
+
+	switch k {
+	case KindFeed:
+		return "feed"
+	case KindLabel:
+		return "label"
+	case KindState:
+		return "state"
+	case KindActiveSearch:
+		return "active search"
+	default:
+		return "unknown"
+	}
+}
+
+// StreamState is one of the system states an item can be in.
+type StreamState string
+
+// States usable with State.
+const (
+	Read        StreamState = "read"
+	Starred     StreamState = "starred"
+	Broadcast   StreamState = "broadcast"
+	Like        StreamState = "like"
+	ReadingList StreamState = "reading-list"
+)
+
+// Prefixes of the stream ID forms.
+const (
+	feedPrefix        = "feed/"
+	userPrefix        = "user/"
+	labelInfix        = "/label/"
+	activeSearchInfix = "/active_search/"
+	stateInfix        = "/state/com.google/"
+	currentUser       = "-"
+)
+
+// Feed returns the stream ID of the feed at `url`.
+func Feed(url string) StreamID {
+	return StreamID(feedPrefix + url)
+}
+
+// Label returns the stream ID of the label or folder `name` of the current
+// user.
+func Label(name string) StreamID {
+	return StreamID(userPrefix + currentUser + labelInfix + name)
+}
+
+// ActiveSearch returns the stream ID of the active search `name` of the
+// current user.
+func ActiveSearch(name string) StreamID {
+	return StreamID(userPrefix + currentUser + activeSearchInfix + name)
+}
+
+// State returns the stream ID of state `s` of the current user.
+func State(s StreamState) StreamID {
+	return StreamID(userPrefix + currentUser + stateInfix + string(s))
+}
+
+// Root returns the stream ID of the top level of the subscription tree.
+func Root() StreamID {
+	return RootStreamID
+}
+
+// ParseStreamID parses `s` into a StreamID, failing if it is not a feed,
+// label, active search or state stream.
+func ParseStreamID(s string) (StreamID, error) {
+
+	id := StreamID(s)
+	if id.Kind() == KindUnknown {
+		return "", errors.Errorf("Invalid stream ID %q", s)
+	}
+
+	return id, nil
+}
+
+func (id StreamID) String() string {
+	return string(id)
+}
+
+// Splits a user stream into the user ID and what follows it, such as
+// "label/Go".
+func (id StreamID) splitUser() (user, rest string, ok bool) {
+
+	s := string(id)
+	if !strings.HasPrefix(s, userPrefix) {
+		return "", "", false
+	}
+
+	parts := strings.SplitN(strings.TrimPrefix(s, userPrefix), "/", 2)
+	if len(parts) != 2 || parts[0] == "" {
+		return "", "", false
+	}
+
+	return parts[0], "/" + parts[1], true
+}
+
+// Kind classifies the stream ID.
+func (id StreamID) Kind() StreamKind {
+
+	if _, ok := id.FeedURL(); ok {
+		return KindFeed
+	}
+
+	if _, ok := id.Label(); ok {
+		return KindLabel
+	}
+
+	if _, ok := id.ActiveSearch(); ok {
+		return KindActiveSearch
+	}
+
+	if _, ok := id.State(); ok {
+		return KindState
+	}
+
+	return KindUnknown
+}
+
+// FeedURL returns the URL of a feed stream.
+func (id StreamID) FeedURL() (string, bool) {
+
+	s := string(id)
+	if !strings.HasPrefix(s, feedPrefix) || len(s) == len(feedPrefix) {
+		return "", false
+	}
+
+	return strings.TrimPrefix(s, feedPrefix), true
+}
+
+// Label returns the name of a label or folder stream.
+func (id StreamID) Label() (string, bool) {
+
+	_, rest, ok := id.splitUser()
+	if !ok || !strings.HasPrefix(rest, labelInfix) || len(rest) == len(labelInfix) {
+		return "", false
+	}
+
+	return strings.TrimPrefix(rest, labelInfix), true
+}
+
+// ActiveSearch returns the name of an active search stream.
+func (id StreamID) ActiveSearch() (string, bool) {
+
+	_, rest, ok := id.splitUser()
+	if !ok || !strings.HasPrefix(rest, activeSearchInfix) || len(rest) == len(activeSearchInfix) {
+		return "", false
+	}
+
+	return strings.TrimPrefix(rest, activeSearchInfix), true
+}
+
+// State returns the state of a state stream, such as Read or "root".
+func (id StreamID) State() (StreamState, bool) {
+
+	_, rest, ok := id.splitUser()
+	if !ok || !strings.HasPrefix(rest, stateInfix) || len(rest) == len(stateInfix) {
+		return "", false
+	}
+
+	return StreamState(strings.TrimPrefix(rest, stateInfix)), true
+}
+
+// UserID returns the user of a user stream: "-" for the current user, or
+// a numeric user ID.
+func (id StreamID) UserID() (string, bool) {
+
+	user, _, ok := id.splitUser()
+	return user, ok
+}
+
+// WithUser returns the user stream for user `userID` instead of the one in
+// id. Other streams are returned unchanged.
+func (id StreamID) WithUser(userID string) StreamID {
+
+	_, rest, ok := id.splitUser()
+	if !ok {
+		return id
+	}
+
+	return StreamID(userPrefix + userID + rest)
+}
+
+// Generic returns id with the user replaced by "-", so that streams of the
+// current user compare equal whichever form the API used.
+func (id StreamID) Generic() StreamID {
+	return id.WithUser(currentUser)
+}
+
+// Equal reports whether id and other name the same stream, treating "-"
+// and a numeric user ID as the same user. Two different user IDs, or "-"
+// and a non-numeric one, do not match.
+func (id StreamID) Equal(other StreamID) bool {
+
+	if id == other {
+		return true
+	}
+
+	user, rest, ok := id.splitUser()
+	otherUser, otherRest, otherOK := other.splitUser()
+	if !ok || !otherOK || rest != otherRest {
+		return false
+	}
+
+	return user == otherUser ||
+		(user == currentUser && isNumeric(otherUser)) ||
+		(otherUser == currentUser && isNumeric(user))
+}
+
+// Reports whether `s` is a non-empty string of decimal digits.
+func isNumeric(s string) bool {
+
+	if s == "" {
+		return false
+	}
+
+	for _, r := range s {
+		if r < '0' || r > '9' {
+			return false
+		}
+	}
+
+	return true
+}
+
+// Resolver turns "user/-/" stream IDs into ones with the concrete user ID
+// from userinfo.GetUserInfo, which is fetched once and cached.
+type Resolver struct {
+	rc *resty.Client
+
+	mu     sync.Mutex
+	userID string
+}
+
+// NewResolver returns a Resolver that looks the user up with rc.
+func NewResolver(rc *resty.Client) *Resolver {
+	return &Resolver{rc: rc}
+}
+
+// Resolve returns id with "-" replaced by the current user's ID. Streams
+// that are not user streams, or that already name a user, are returned
+// unchanged.
+func (r *Resolver) Resolve(ctx context.Context, id StreamID) (StreamID, error) {
+
+	if user, ok := id.UserID(); !ok || user != currentUser {
+		return id, nil
+	}
+
+	userID, err := r.UserID(ctx)
+	if err != nil {
+		return "", err
+	}
+
+	return id.WithUser(userID), nil
+}
+
+// UserID returns the current user's ID.
+func (r *Resolver) UserID(ctx context.Context) (string, error) {
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.userID != "" {
+		return r.userID, nil
+	}
+
+	info, err := userinfo.GetUserInfoContext(ctx, r.rc)
+	if err != nil {
+		return "", err
+	}
+
+	if info.UserID == "" {
+		return "", errors.New("Unable to resolve stream ID: user info has no user ID")
+	}
+
+	r.userID = info.UserID
+	return r.userID, nil
+}
@@ -0,0 +1,116 @@
+package stream
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+
+	"github.com/go-resty/resty/v2"
+)
+
+func TestStreamIDConstructors(t *testing.T) {
+	cases := map[StreamID]string{
+		Feed("https://fedoramagazine.org/feed/"): "feed/https://fedoramagazine.org/feed/",
+		Label("Go"):                              "user/-/label/Go",
+		State(Read):                              "user/-/state/com.google/read",
+		State(ReadingList):                       "user/-/state/com.google/reading-list",
+		ActiveSearch("Go releases"):              "user/-/active_search/Go releases",
+		Root():                                   "user/-/state/com.google/root",
+	}
+
+	for id, want := range cases {
+		if string(id) != want {
+			t.Fatalf("built %q, want %q", id, want)
+		}
+	}
+}
+
+func TestParseStreamID(t *testing.T) {
+	cases := []struct {
+		in    string
+		kind  StreamKind
+		value string
+		user  string
+	}{
+		{"feed/https://fedoramagazine.org/feed/", KindFeed, "https://fedoramagazine.org/feed/", ""},
+		{"user/-/label/Go/Tools", KindLabel, "Go/Tools", "-"},
+		{"user/1005869311/state/com.google/starred", KindState, "starred", "1005869311"},
+		{"user/-/state/com.google/root", KindState, "root", "-"},
+		{"user/1005869311/active_search/go/releases", KindActiveSearch, "go/releases", "1005869311"},
+	}
+
+	for _, tc := range cases {
+		id, err := ParseStreamID(tc.in)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		var value string
+		switch id.Kind() {
+		case KindFeed:
+			value, _ = id.FeedURL()
+		case KindLabel:
+			value, _ = id.Label()
+		case KindState:
+			state, _ := id.State()
+			value = string(state)
+		case KindActiveSearch:
+			value, _ = id.ActiveSearch()
+		}
+		user, _ := id.UserID()
+
+		if id.Kind() != tc.kind || value != tc.value || user != tc.user {
+			t.Fatalf("%q parsed as %s %q of user %q, want %s %q of user %q", tc.in, id.Kind(), value, user, tc.kind, tc.value, tc.user)
+		}
+	}
+
+	for _, in := range []string{"", "feed/", "user/-/label/", "user//label/Go", "user/-/active_search/", "pop/topic/top/language/en", "user/-"} {
+		if id, err := ParseStreamID(in); err == nil {
+			t.Fatalf("ParseStreamID(%q) = %q, want an error", in, id)
+		}
+	}
+
+	if !StreamID("user/1005869311/label/Go").Equal(Label("Go")) || Label("Go").Equal(Label("Rust")) {
+		t.Fatal("Equal does not ignore the user ID")
+	}
+
+	if StreamID("user/1005869311/label/Go").Equal("user/1005869312/label/Go") || Label("Go").Equal("user/bob/label/Go") {
+		t.Fatal("Equal matches streams of different users")
+	}
+}
+
+func TestResolver(t *testing.T) {
+	var requests int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&requests, 1)
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"userId": "1005869311", "userName": "hyperreal"}`))
+	}))
+	defer srv.Close()
+
+	r := NewResolver(resty.New().SetHostURL(srv.URL))
+
+	cases := map[StreamID]StreamID{
+		Label("Go"):                     "user/1005869311/label/Go",
+		State(Starred):                  "user/1005869311/state/com.google/starred",
+		"user/42/label/Go":              "user/42/label/Go",
+		Feed("https://example.com/rss"): "feed/https://example.com/rss",
+	}
+
+	for in, want := range cases {
+		got, err := r.Resolve(context.Background(), in)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if got != want {
+			t.Fatalf("Resolve(%q) = %q, want %q", in, got, want)
+		}
+	}
+
+	if requests != 1 {
+		t.Fatalf("looked the user up %d times, want 1", requests)
+	}
+}
@@ -0,0 +1,101 @@
+package subscription
+
+import (
+	"context"
+
+	"github.com/go-resty/resty/v2"
+	"github.com/hyperreal64/go-inoreader/stream"
+	"github.com/pkg/errors"
+)
+
+// EditAction is what a subscription/edit request does to the feed.
+type EditAction string
+
+const (
+	// ActionEdit changes the title or folders of a subscribed feed.
+	ActionEdit        EditAction = "edit"
+	ActionSubscribe   EditAction = "subscribe"
+	ActionUnsubscribe EditAction = "unsubscribe"
+)
+
+// EditOptions are the parameters of a subscription/edit request.
+type EditOptions struct {
+	// Action defaults to ActionEdit.
+	Action EditAction
+
+	// Feed is the feed stream, such as stream.Feed(url).
+	Feed stream.StreamID
+
+	// Title, if set, renames the subscription.
+	Title string
+
+	// Add and Remove, if set, are folders to put the feed in or take it
+	// out of, such as stream.Label("Tech").
+	Add    stream.StreamID
+	Remove stream.StreamID
+}
+
+// Returns the query parameters of o, failing if a stream ID is of the wrong
+// kind.
+func (o *EditOptions) params() (map[string]string, error) {
+
+	if o.Feed.Kind() != stream.KindFeed {
+		return nil, errors.Errorf("Unable to edit subscription: %q is not a feed", o.Feed)
+	}
+
+	for _, folder := range []stream.StreamID{o.Add, o.Remove} {
+		if folder != "" && folder.Kind() != stream.KindLabel {
+			return nil, errors.Errorf("Unable to edit subscription: %q is not a folder", folder)
+		}
+	}
+
+	action := o.Action
+	if action == "" {
+		action = ActionEdit
+	}
+
+	params := map[string]string{
+		"ac": string(action),
+		"s":  string(o.Feed),
+	}
+
+	if o.Title != "" {
+		params["t"] = o.Title
+	}
+
+	if o.Add != "" {
+		params["a"] = string(o.Add)
+	}
+
+	if o.Remove != "" {
+		params["r"] = string(o.Remove)
+	}
+
+	return params, nil
+}
+
+// Edits the subscription described by opts. Sends a POST request.
+func EditSubscriptionWithOptions(ctx context.Context, rc *resty.Client, opts *EditOptions) error {
+
+	if opts == nil {
+		return errors.New("Unable to edit subscription: no options")
+	}
+
+	params, err := opts.params()
+	if err != nil {
+		return err
+	}
+
+	return EditSubscriptionContext(ctx, rc, params)
+}
+
+// Subscribes to `feed`, such as stream.Feed(url). Sends a POST request and
+// returns the JSON response as a QuickAdd struct.
+func QuickAddFeed(ctx context.Context, rc *resty.Client, feed stream.StreamID) (*QuickAdd, error) {
+
+	if feed.Kind() != stream.KindFeed {
+		return nil, errors.Errorf("Unable to add subscription: %q is not a feed", feed)
+	}
+
+	return QuickAddSubscriptionContext(ctx, rc, map[string]string{"quickadd": string(feed)})
+}
@@ -0,0 +1,61 @@
+package subscription
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/go-resty/resty/v2"
+	"github.com/hyperreal64/go-inoreader/stream"
+)
+
+func TestEditSubscriptionWithOptions(t *testing.T) {
+	var gotURI string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotURI = r.URL.Path + "?" + r.URL.RawQuery
+		if r.URL.Path == "/subscription/quickadd" {
+			w.Header().Set("Content-Type", "application/json")
+			w.Write([]byte(`{"query": "feed/https://a.example/rss", "numResults": 1, "streamId": "feed/https://a.example/rss"}`))
+			return
+		}
+		w.Write([]byte("OK"))
+	}))
+	defer srv.Close()
+
+	rc := resty.New().SetHostURL(srv.URL)
+	err := EditSubscriptionWithOptions(context.Background(), rc, &EditOptions{
+		Feed:   stream.Feed("https://a.example/rss"),
+		Title:  "A",
+		Add:    stream.Label("News"),
+		Remove: stream.Label("Tech"),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := "/subscription/edit?a=user%2F-%2Flabel%2FNews&ac=edit&r=user%2F-%2Flabel%2FTech&s=feed%2Fhttps%3A%2F%2Fa.example%2Frss&t=A"
+	if gotURI != want {
+		t.Fatalf("requested %s, want %s", gotURI, want)
+	}
+
+	quickadd, err := QuickAddFeed(context.Background(), rc, stream.Feed("https://a.example/rss"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if gotURI != "/subscription/quickadd?quickadd=feed%2Fhttps%3A%2F%2Fa.example%2Frss" || quickadd.StreamID != stream.Feed("https://a.example/rss") {
+		t.Fatalf("requested %s, decoded %#v", gotURI, quickadd)
+	}
+
+	invalid := []*EditOptions{
+		nil,
+		{Feed: stream.Label("Tech")},
+		{Feed: stream.Feed("https://a.example/rss"), Add: stream.State(stream.Starred)},
+	}
+	for _, opts := range invalid {
+		if err := EditSubscriptionWithOptions(context.Background(), rc, opts); err == nil {
+			t.Fatalf("EditSubscriptionWithOptions(%#v) succeeded", opts)
+		}
+	}
+}
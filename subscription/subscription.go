@@ -1,62 +1,78 @@
 package subscription
 
 import (
+	"context"
 	"encoding/json"
 
 	"github.com/go-resty/resty/v2"
+	"github.com/hyperreal64/go-inoreader/api"
+	"github.com/hyperreal64/go-inoreader/stream"
 	"github.com/pkg/errors"
 )
 
-// API URLs for resty.Client requests
+// API endpoint paths, relative to api.DefaultBaseURL
 const (
-	addSubURL         = "https://www.inoreader.com/reader/api/0/subscription/quickadd"
-	editSubURL        = "https://www.inoreader.com/reader/api/0/subscription/edit"
-	unreadCountersURL = "https://www.inoreader.com/reader/api/0/unread-count"
-	subListURL        = "https://www.inoreader.com/reader/api/0/subscription/list"
+	addSubURL         = "subscription/quickadd"
+	editSubURL        = "subscription/edit"
+	unreadCountersURL = "unread-count"
+	subListURL        = "subscription/list"
 )
 
 // QuickAdd JSON response
 type QuickAdd struct {
-	Query      string `json:"query"`
-	NumResults int    `json:"numResults"`
-	StreamID   string `json:"streamId"`
-	StreamName string `json:"streamName"`
+	Query      string          `json:"query"`
+	NumResults int             `json:"numResults"`
+	StreamID   stream.StreamID `json:"streamId"`
+	StreamName string          `json:"streamName"`
 }
 
 // UnreadCounters JSON response
 type UnreadCounters struct {
 	Max          int `json:"max"`
 	Unreadcounts []struct {
-		ID                      string      `json:"id"`
-		Count                   json.Number `json:"count"`
-		NewestItemTimestampUsec string      `json:"newestItemTimestampUsec"`
+		ID                      stream.StreamID `json:"id"`
+		Count                   json.Number     `json:"count"`
+		NewestItemTimestampUsec api.UsecTime    `json:"newestItemTimestampUsec"`
 	} `json:"unreadcounts"`
 }
 
 // SubscriptionList JSON response
 type SubscriptionList struct {
 	Subscriptions []struct {
-		ID            string        `json:"id"`
-		FeedType      string        `json:"feedType"`
-		Title         string        `json:"title"`
-		Categories    []interface{} `json:"categories"`
-		Sortid        string        `json:"sortid"`
-		Firstitemmsec int64         `json:"firstitemmsec"`
-		URL           string        `json:"url"`
-		HTMLURL       string        `json:"htmlUrl"`
-		IconURL       string        `json:"iconUrl"`
+		ID            stream.StreamID `json:"id"`
+		FeedType      string          `json:"feedType"`
+		Title         string          `json:"title"`
+		Categories    []Category      `json:"categories"`
+		Sortid        string          `json:"sortid"`
+		Firstitemmsec api.MsecTime    `json:"firstitemmsec"`
+		URL           string          `json:"url"`
+		HTMLURL       string          `json:"htmlUrl"`
+		IconURL       string          `json:"iconUrl"`
 	} `json:"subscriptions"`
 }
 
+// Category JSON response; a folder a subscription is in.
+type Category struct {
+	ID    stream.StreamID `json:"id"`
+	Label string          `json:"label"`
+}
+
 // Quick add a subscription as specified in the query parameters.
 // Unlike other POST requests to the Inoreader API server, this one returns
-// a JSON response, which gets stored into a QuickAdd struct.
+// a JSON response, which gets stored into a QuickAdd struct. QuickAddFeed
+// takes the feed stream ID instead.
 func QuickAddSubscription(rc *resty.Client, params map[string]string) (quickadd *QuickAdd, err error) {
+	return QuickAddSubscriptionContext(context.Background(), rc, params)
+}
+
+// Same as QuickAddSubscription, but the request is bound to ctx.
+func QuickAddSubscriptionContext(ctx context.Context, rc *resty.Client, params map[string]string) (quickadd *QuickAdd, err error) {
 
 	resp, err := rc.R().
+		SetContext(ctx).
 		SetQueryParams(params).
-		Post(addSubURL)
-	if err != nil {
+		Post(api.Endpoint(rc, addSubURL))
+	if err := api.CheckResponseContext(ctx, resp, err); err != nil {
 		return nil, err
 	}
 
@@ -68,12 +84,19 @@ func QuickAddSubscription(rc *resty.Client, params map[string]string) (quickadd
 }
 
 // Edit subscription specified in query parameters. Sends a POST request.
+// EditSubscriptionWithOptions takes the stream IDs instead.
 func EditSubscription(rc *resty.Client, params map[string]string) error {
+	return EditSubscriptionContext(context.Background(), rc, params)
+}
+
+// Same as EditSubscription, but the request is bound to ctx.
+func EditSubscriptionContext(ctx context.Context, rc *resty.Client, params map[string]string) error {
 
-	_, err := rc.R().
+	resp, err := rc.R().
+		SetContext(ctx).
 		SetQueryParams(params).
-		Post(editSubURL)
-	if err != nil {
+		Post(api.Endpoint(rc, editSubURL))
+	if err := api.CheckResponseContext(ctx, resp, err); err != nil {
 		return err
 	}
 
@@ -83,9 +106,14 @@ func EditSubscription(rc *resty.Client, params map[string]string) error {
 // Get list of subscriptions. Sends a GET request and returns JSON response as
 // SubscriptionList struct.
 func GetSubscriptionList(rc *resty.Client) (sublist *SubscriptionList, err error) {
+	return GetSubscriptionListContext(context.Background(), rc)
+}
+
+// Same as GetSubscriptionList, but the request is bound to ctx.
+func GetSubscriptionListContext(ctx context.Context, rc *resty.Client) (sublist *SubscriptionList, err error) {
 
-	resp, err := rc.R().Get(subListURL)
-	if err != nil {
+	resp, err := rc.R().SetContext(ctx).Get(api.Endpoint(rc, subListURL))
+	if err := api.CheckResponseContext(ctx, resp, err); err != nil {
 		return nil, err
 	}
 
@@ -99,9 +127,14 @@ func GetSubscriptionList(rc *resty.Client) (sublist *SubscriptionList, err error
 // Get the number of unread items for subscriptions. Sends a GET request and
 // returns JSON response as UnreadCounters struct.
 func GetUnreadCounters(rc *resty.Client) (uc *UnreadCounters, err error) {
+	return GetUnreadCountersContext(context.Background(), rc)
+}
+
+// Same as GetUnreadCounters, but the request is bound to ctx.
+func GetUnreadCountersContext(ctx context.Context, rc *resty.Client) (uc *UnreadCounters, err error) {
 
-	resp, err := rc.R().Get(unreadCountersURL)
-	if err != nil {
+	resp, err := rc.R().SetContext(ctx).Get(api.Endpoint(rc, unreadCountersURL))
+	if err := api.CheckResponseContext(ctx, resp, err); err != nil {
 		return nil, err
 	}
 
@@ -5,6 +5,7 @@ import (
 	"testing"
 
 	"github.com/hyperreal64/go-inoreader/config"
+	"github.com/hyperreal64/go-inoreader/stream"
 )
 
 func TestQuickAddSubscription(t *testing.T) {
@@ -13,7 +14,7 @@ func TestQuickAddSubscription(t *testing.T) {
 	defer cancel()
 
 	params := map[string]string{
-		"quickadd": "feed/https://fedoramagazine.org/feed/",
+		"quickadd": stream.Feed("https://fedoramagazine.org/feed/").String(),
 	}
 
 	quickadd, err := QuickAddSubscription(rc, params)
@@ -32,7 +33,7 @@ func TestEditSubscription(t *testing.T) {
 	feedURL := "https://fedoramagazine.org/feed/"
 	params := map[string]string{
 		"ac": "unsubscribe",
-		"s":  "feed/" + feedURL,
+		"s":  stream.Feed(feedURL).String(),
 	}
 
 	if err := EditSubscription(rc, params); err != nil {
@@ -0,0 +1,166 @@
+package tags
+
+import (
+	"context"
+
+	"github.com/go-resty/resty/v2"
+	"github.com/hyperreal64/go-inoreader/api"
+	"github.com/hyperreal64/go-inoreader/stream"
+	"github.com/hyperreal64/go-inoreader/subscription"
+	"github.com/pkg/errors"
+)
+
+// DefaultDryRunMaxItems is the number of items a dry run lists unless
+// DeleteOptions.DryRunMaxItems says otherwise: one stream/items/ids page.
+const DefaultDryRunMaxItems = stream.MaxItemIDsCount
+
+// DeleteOptions control DeleteTag.
+type DeleteOptions struct {
+	// MoveSubscriptionsTo, if set, is the folder the subscriptions of a
+	// deleted folder are moved to first. stream.Root() moves them to the
+	// top level.
+	MoveSubscriptionsTo stream.StreamID
+
+	// DryRun lists what would be affected without changing anything.
+	DryRun bool
+
+	// DryRunMaxItems caps the items a dry run lists, since every page of
+	// them counts against the read zone. Zero means DefaultDryRunMaxItems
+	// and a negative value lists none.
+	DryRunMaxItems int
+}
+
+// DeleteReport describes what a DeleteTag call affected, or would affect
+// for a dry run.
+type DeleteReport struct {
+	Label stream.StreamID
+
+	// Subscriptions are the feeds in the folder.
+	Subscriptions []stream.StreamID
+
+	// Items are the short IDs of the items carrying the label. They are
+	// only listed for a dry run, and at most DryRunMaxItems of them.
+	Items []string
+
+	// ItemsTruncated is set when the label may carry more items than
+	// Items lists.
+	ItemsTruncated bool
+
+	// Moved are the subscriptions that were moved to MoveSubscriptionsTo.
+	Moved []stream.StreamID
+}
+
+// Deletes the tag or folder `label`, such as stream.Label("Go"), through
+// disable-tag. With opts.MoveSubscriptionsTo, the folder's subscriptions
+// are moved there first; if a move fails, the folder is left in place and
+// the report lists the subscriptions moved so far.
+func DeleteTag(ctx context.Context, rc *resty.Client, label stream.StreamID, opts *DeleteOptions) (*DeleteReport, error) {
+
+	if label.Kind() != stream.KindLabel {
+		return nil, errors.Errorf("Unable to delete tag: %q is not a label", label)
+	}
+
+	o := DeleteOptions{}
+	if opts != nil {
+		o = *opts
+	}
+
+	target := o.MoveSubscriptionsTo
+	if target != "" {
+		if target.Kind() != stream.KindLabel && !target.Equal(stream.Root()) {
+			return nil, errors.Errorf("Unable to move subscriptions: %q is not a folder", target)
+		}
+		if target.Equal(label) {
+			return nil, errors.Errorf("Unable to move subscriptions: %q is the folder being deleted", target)
+		}
+	}
+
+	report := &DeleteReport{Label: label}
+
+	sublist, err := subscription.GetSubscriptionListContext(ctx, rc)
+	if err != nil {
+		return nil, errors.Wrap(err, "Unable to list subscriptions")
+	}
+
+	for _, sub := range sublist.Subscriptions {
+		for _, c := range sub.Categories {
+			if c.ID.Equal(label) {
+				report.Subscriptions = append(report.Subscriptions, sub.ID)
+				break
+			}
+		}
+	}
+
+	if o.DryRun {
+		max := o.DryRunMaxItems
+		if max == 0 {
+			max = DefaultDryRunMaxItems
+		}
+
+		if max > 0 {
+			report.Items, report.ItemsTruncated, err = listItems(ctx, rc, label, max)
+			if err != nil {
+				return nil, errors.Wrap(err, "Unable to list items")
+			}
+		}
+
+		return report, nil
+	}
+
+	if target != "" {
+		for _, feed := range report.Subscriptions {
+			edit := &subscription.EditOptions{Feed: feed, Remove: label}
+			if !target.Equal(stream.Root()) {
+				edit.Add = target
+			}
+
+			if err := subscription.EditSubscriptionWithOptions(ctx, rc, edit); err != nil {
+				return report, errors.Wrapf(err, "Unable to move %s", feed)
+			}
+			report.Moved = append(report.Moved, feed)
+		}
+	}
+
+	resp, err := rc.R().
+		SetContext(ctx).
+		SetQueryParam("s", string(label)).
+		Post(api.Endpoint(rc, disableTagURL))
+	if err := api.CheckResponseContext(ctx, resp, err); err != nil {
+		return report, err
+	}
+
+	return report, nil
+}
+
+// Returns the short IDs of at most `max` items of `label`, and whether the
+// stream went on past them.
+func listItems(ctx context.Context, rc *resty.Client, label stream.StreamID, max int) ([]string, bool, error) {
+
+	var ids []string
+	opts := stream.ItemIDsOptions{StreamID: label}
+	for {
+		opts.Count = max - len(ids)
+		if opts.Count > stream.MaxItemIDsCount {
+			opts.Count = stream.MaxItemIDsCount
+		}
+
+		page, err := stream.GetItemIDs(ctx, rc, &opts)
+		if err != nil {
+			return nil, false, err
+		}
+
+		for _, ref := range page.ItemRefs {
+			ids = append(ids, ref.ID)
+		}
+
+		if page.Continuation == "" || len(page.ItemRefs) == 0 {
+			return ids, false, nil
+		}
+
+		if len(ids) >= max {
+			return ids[:max], true, nil
+		}
+
+		opts.Continuation = page.Continuation
+	}
+}
@@ -0,0 +1,131 @@
+package tags
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/go-resty/resty/v2"
+	"github.com/hyperreal64/go-inoreader/stream"
+)
+
+// Returns a fake API with two subscriptions in folder Tech, and the POST
+// requests it received as "path?query".
+func deleteServer(t *testing.T) (*httptest.Server, *[]string) {
+
+	var posts []string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodPost {
+			posts = append(posts, r.URL.Path+"?"+r.URL.RawQuery)
+			w.Write([]byte("OK"))
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		switch r.URL.Path {
+		case "/subscription/list":
+			w.Write([]byte(`{"subscriptions": [
+				{"id": "feed/https://a.example/rss", "categories": [{"id": "user/1005869311/label/Tech", "label": "Tech"}]},
+				{"id": "feed/https://b.example/rss", "categories": [{"id": "user/1005869311/label/News", "label": "News"}]},
+				{"id": "feed/https://c.example/rss", "categories": [{"id": "user/1005869311/label/Tech", "label": "Tech"}]}
+			]}`))
+		case "/stream/items/ids":
+			if r.FormValue("s") != "user/-/label/Tech" {
+				t.Errorf("listed items of %s", r.FormValue("s"))
+			}
+			if r.FormValue("n") == "" {
+				t.Error("listed items without a count")
+			}
+
+			// Pages of at most n items.
+			refs, next := `{"id": "1"}, {"id": "2"}`, ""
+			switch {
+			case r.FormValue("n") == "1" && r.FormValue("c") == "":
+				refs, next = `{"id": "1"}`, "page2"
+			case r.FormValue("n") == "1":
+				refs = `{"id": "2"}`
+			}
+			fmt.Fprintf(w, `{"itemRefs": [%s], "continuation": "%s"}`, refs, next)
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	t.Cleanup(srv.Close)
+
+	return srv, &posts
+}
+
+func TestDeleteTagDryRun(t *testing.T) {
+	srv, posts := deleteServer(t)
+	rc := resty.New().SetHostURL(srv.URL)
+
+	report, err := DeleteTag(context.Background(), rc, stream.Label("Tech"), &DeleteOptions{DryRun: true, MoveSubscriptionsTo: stream.Root()})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if fmt.Sprint(report.Subscriptions) != "[feed/https://a.example/rss feed/https://c.example/rss]" || fmt.Sprint(report.Items) != "[1 2]" {
+		t.Fatalf("report %#v", report)
+	}
+
+	if len(*posts) != 0 || len(report.Moved) != 0 || report.ItemsTruncated {
+		t.Fatalf("dry run changed something: %v", *posts)
+	}
+
+	report, err = DeleteTag(context.Background(), rc, stream.Label("Tech"), &DeleteOptions{DryRun: true, DryRunMaxItems: 1})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if fmt.Sprint(report.Items) != "[1]" || !report.ItemsTruncated {
+		t.Fatalf("report %#v, want item 1 of more", report)
+	}
+
+	report, err = DeleteTag(context.Background(), rc, stream.Label("Tech"), &DeleteOptions{DryRun: true, DryRunMaxItems: -1})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if report.Items != nil || len(report.Subscriptions) != 2 {
+		t.Fatalf("report %#v, want feeds and no items", report)
+	}
+}
+
+func TestDeleteTagMove(t *testing.T) {
+	srv, posts := deleteServer(t)
+	rc := resty.New().SetHostURL(srv.URL)
+
+	report, err := DeleteTag(context.Background(), rc, stream.Label("Tech"), &DeleteOptions{MoveSubscriptionsTo: stream.Label("News")})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := []string{
+		"/subscription/edit?a=user%2F-%2Flabel%2FNews&ac=edit&r=user%2F-%2Flabel%2FTech&s=feed%2Fhttps%3A%2F%2Fa.example%2Frss",
+		"/subscription/edit?a=user%2F-%2Flabel%2FNews&ac=edit&r=user%2F-%2Flabel%2FTech&s=feed%2Fhttps%3A%2F%2Fc.example%2Frss",
+		"/disable-tag?s=user%2F-%2Flabel%2FTech",
+	}
+	if fmt.Sprint(*posts) != fmt.Sprint(want) {
+		t.Fatalf("sent %v, want %v", *posts, want)
+	}
+
+	if len(report.Moved) != 2 || report.Items != nil {
+		t.Fatalf("report %#v", report)
+	}
+
+	invalid := []struct {
+		label stream.StreamID
+		opts  *DeleteOptions
+	}{
+		{stream.State(stream.Starred), nil},
+		{stream.Label("Tech"), &DeleteOptions{MoveSubscriptionsTo: stream.Feed("https://a.example/rss")}},
+		{stream.Label("Tech"), &DeleteOptions{MoveSubscriptionsTo: "user/1005869311/label/Tech"}},
+	}
+	for _, tc := range invalid {
+		if _, err := DeleteTag(context.Background(), rc, tc.label, tc.opts); err == nil {
+			t.Fatalf("DeleteTag(%q, %#v) succeeded", tc.label, tc.opts)
+		}
+	}
+}
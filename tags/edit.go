@@ -0,0 +1,203 @@
+package tags
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"strings"
+
+	"github.com/go-resty/resty/v2"
+	"github.com/hyperreal64/go-inoreader/api"
+	"github.com/hyperreal64/go-inoreader/stream"
+	"github.com/pkg/errors"
+)
+
+// DefaultEditChunkSize is the number of item IDs sent per edit-tag request
+// unless EditOptions.ChunkSize says otherwise.
+const DefaultEditChunkSize = 250
+
+// EditOptions control how EditItemTags splits its requests.
+type EditOptions struct {
+	// ChunkSize is the number of item IDs sent per request.
+	ChunkSize int
+}
+
+// ChunkError is the failure of one edit-tag request.
+type ChunkError struct {
+	// IDs are the item IDs, as given, that the request was for.
+	IDs []string
+	Err error
+}
+
+func (e *ChunkError) Error() string {
+	return fmt.Sprintf("%d items: %v", len(e.IDs), e.Err)
+}
+
+func (e *ChunkError) Unwrap() error {
+	return e.Err
+}
+
+// EditError reports the chunks of an EditItemTags call that failed. The
+// items of the other chunks were updated.
+type EditError struct {
+	// Succeeded are the item IDs, as given, that were updated.
+	Succeeded []string
+
+	Failed []*ChunkError
+}
+
+func (e *EditError) Error() string {
+
+	msgs := make([]string, len(e.Failed))
+	for i, c := range e.Failed {
+		msgs[i] = c.Error()
+	}
+
+	return fmt.Sprintf("Unable to edit tags of %d of %d items: %s",
+		len(e.FailedIDs()), len(e.FailedIDs())+len(e.Succeeded), strings.Join(msgs, "; "))
+}
+
+// Unwrap returns the error of the first failed chunk, so that errors.Is
+// and api.IsRateLimited see it.
+func (e *EditError) Unwrap() error {
+
+	if len(e.Failed) == 0 {
+		return nil
+	}
+
+	return e.Failed[0].Err
+}
+
+// FailedIDs returns the item IDs of every failed chunk.
+func (e *EditError) FailedIDs() []string {
+
+	var ids []string
+	for _, c := range e.Failed {
+		ids = append(ids, c.IDs...)
+	}
+
+	return ids
+}
+
+// Adds the streams in `add` to, and removes the streams in `remove` from,
+// the items with the given IDs, in short or long form. The IDs are sent in
+// chunks, one edit-tag request each, with one `i` parameter per item.
+//
+// If some chunks fail, the others are still sent and an *EditError lists
+// which items were and were not updated. Once the context is done or the
+// API answers 429, the remaining chunks are not sent and are reported as
+// failed with that error.
+func EditItemTags(ctx context.Context, rc *resty.Client, add, remove []stream.StreamID, ids []string, opts *EditOptions) error {
+
+	if len(add) == 0 && len(remove) == 0 {
+		return errors.New("Unable to edit tags: no streams to add or remove")
+	}
+
+	chunkSize := DefaultEditChunkSize
+	if opts != nil && opts.ChunkSize > 0 {
+		chunkSize = opts.ChunkSize
+	}
+
+	longIDs := make([]string, len(ids))
+	for i, s := range ids {
+		id, err := stream.ParseItemID(s)
+		if err != nil {
+			return err
+		}
+		longIDs[i] = id.Long()
+	}
+
+	editErr := &EditError{}
+	var stop error
+	for start := 0; start < len(ids); start += chunkSize {
+		end := start + chunkSize
+		if end > len(ids) {
+			end = len(ids)
+		}
+
+		err := stop
+		if err == nil {
+			err = editChunk(ctx, rc, add, remove, longIDs[start:end])
+		}
+
+		if err != nil {
+			editErr.Failed = append(editErr.Failed, &ChunkError{IDs: ids[start:end], Err: err})
+			if ctx.Err() != nil || api.IsRateLimited(err) {
+				stop = err
+			}
+			continue
+		}
+
+		editErr.Succeeded = append(editErr.Succeeded, ids[start:end]...)
+	}
+
+	if len(editErr.Failed) > 0 {
+		return editErr
+	}
+
+	return nil
+}
+
+// Sends one edit-tag request for `ids`.
+func editChunk(ctx context.Context, rc *resty.Client, add, remove []stream.StreamID, ids []string) error {
+
+	form := url.Values{}
+	for _, id := range add {
+		form.Add("a", string(id))
+	}
+
+	for _, id := range remove {
+		form.Add("r", string(id))
+	}
+
+	for _, id := range ids {
+		form.Add("i", id)
+	}
+
+	resp, err := rc.R().
+		SetContext(ctx).
+		SetFormDataFromValues(form).
+		Post(api.Endpoint(rc, editTagURL))
+
+	return api.CheckResponseContext(ctx, resp, err)
+}
+
+// Marks the items with the given IDs as read.
+func MarkRead(ctx context.Context, rc *resty.Client, ids ...string) error {
+	return EditItemTags(ctx, rc, []stream.StreamID{stream.State(stream.Read)}, nil, ids, nil)
+}
+
+// Marks the items with the given IDs as unread.
+func MarkUnread(ctx context.Context, rc *resty.Client, ids ...string) error {
+	return EditItemTags(ctx, rc, nil, []stream.StreamID{stream.State(stream.Read)}, ids, nil)
+}
+
+// Stars the items with the given IDs.
+func Star(ctx context.Context, rc *resty.Client, ids ...string) error {
+	return EditItemTags(ctx, rc, []stream.StreamID{stream.State(stream.Starred)}, nil, ids, nil)
+}
+
+// Removes the star from the items with the given IDs.
+func Unstar(ctx context.Context, rc *resty.Client, ids ...string) error {
+	return EditItemTags(ctx, rc, nil, []stream.StreamID{stream.State(stream.Starred)}, ids, nil)
+}
+
+// Puts `label`, such as stream.Label("Go"), on the items with the given IDs.
+func AddLabel(ctx context.Context, rc *resty.Client, label stream.StreamID, ids ...string) error {
+
+	if label.Kind() != stream.KindLabel {
+		return errors.Errorf("Unable to add label: %q is not a label", label)
+	}
+
+	return EditItemTags(ctx, rc, []stream.StreamID{label}, nil, ids, nil)
+}
+
+// Takes `label` off the items with the given IDs.
+func RemoveLabel(ctx context.Context, rc *resty.Client, label stream.StreamID, ids ...string) error {
+
+	if label.Kind() != stream.KindLabel {
+		return errors.Errorf("Unable to remove label: %q is not a label", label)
+	}
+
+	return EditItemTags(ctx, rc, nil, []stream.StreamID{label}, ids, nil)
+}
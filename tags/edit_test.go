@@ -0,0 +1,112 @@
+package tags
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+
+	"github.com/go-resty/resty/v2"
+	"github.com/hyperreal64/go-inoreader/api"
+	"github.com/hyperreal64/go-inoreader/stream"
+	"github.com/pkg/errors"
+)
+
+// Returns a server answering edit-tag requests that contain item `failID`
+// with `status`, and the forms it received.
+func editServer(t *testing.T, failID string, status int) (*httptest.Server, *[]map[string][]string) {
+
+	var forms []map[string][]string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if err := r.ParseForm(); err != nil {
+			t.Error(err)
+		}
+		forms = append(forms, r.PostForm)
+
+		for _, id := range r.PostForm["i"] {
+			if id == failID {
+				w.WriteHeader(status)
+				return
+			}
+		}
+
+		w.Write([]byte("OK"))
+	}))
+	t.Cleanup(srv.Close)
+
+	return srv, &forms
+}
+
+func itemIDs(n int) []string {
+
+	ids := make([]string, n)
+	for i := range ids {
+		ids[i] = strconv.Itoa(i + 1)
+	}
+
+	return ids
+}
+
+func TestMarkRead(t *testing.T) {
+	srv, forms := editServer(t, "", 0)
+	rc := resty.New().SetHostURL(srv.URL)
+
+	if err := MarkRead(context.Background(), rc, itemIDs(DefaultEditChunkSize+1)...); err != nil {
+		t.Fatal(err)
+	}
+
+	if len(*forms) != 2 || len((*forms)[0]["i"]) != DefaultEditChunkSize || len((*forms)[1]["i"]) != 1 {
+		t.Fatalf("sent %d requests, want 2 with %d and 1 items", len(*forms), DefaultEditChunkSize)
+	}
+
+	first := (*forms)[0]
+	if fmt.Sprint(first["a"]) != "[user/-/state/com.google/read]" || first["r"] != nil || first["i"][0] != "tag:google.com,2005:reader/item/0000000000000001" {
+		t.Fatalf("sent form %v", first)
+	}
+
+	if err := RemoveLabel(context.Background(), rc, stream.Label("Go"), "tag:google.com,2005:reader/item/0000000000000002"); err != nil {
+		t.Fatal(err)
+	}
+
+	if last := (*forms)[2]; fmt.Sprint(last["r"]) != "[user/-/label/Go]" || last["a"] != nil {
+		t.Fatalf("sent form %v", last)
+	}
+
+	if err := AddLabel(context.Background(), rc, stream.State(stream.Starred), "1"); err == nil {
+		t.Fatal("AddLabel accepted a state")
+	}
+}
+
+func TestEditItemTagsPartialFailure(t *testing.T) {
+	srv, forms := editServer(t, "tag:google.com,2005:reader/item/0000000000000003", http.StatusServiceUnavailable)
+	rc := resty.New().SetHostURL(srv.URL)
+
+	err := EditItemTags(context.Background(), rc, []stream.StreamID{stream.State(stream.Starred)}, nil, itemIDs(5), &EditOptions{ChunkSize: 2})
+
+	var editErr *EditError
+	if !errors.As(err, &editErr) {
+		t.Fatalf("error = %v, want an *EditError", err)
+	}
+
+	if fmt.Sprint(editErr.Succeeded) != "[1 2 5]" || fmt.Sprint(editErr.FailedIDs()) != "[3 4]" || !api.IsServerError(err) || len(*forms) != 3 {
+		t.Fatalf("succeeded %v, failed %v after %d requests", editErr.Succeeded, editErr.FailedIDs(), len(*forms))
+	}
+}
+
+func TestEditItemTagsRateLimited(t *testing.T) {
+	srv, forms := editServer(t, "tag:google.com,2005:reader/item/0000000000000001", http.StatusTooManyRequests)
+	rc := resty.New().SetHostURL(srv.URL)
+
+	err := EditItemTags(context.Background(), rc, nil, []stream.StreamID{stream.State(stream.Read)}, itemIDs(5), &EditOptions{ChunkSize: 2})
+
+	var editErr *EditError
+	if !errors.As(err, &editErr) || !api.IsRateLimited(err) {
+		t.Fatalf("error = %v, want a rate limited *EditError", err)
+	}
+
+	if len(editErr.Failed) != 3 || len(editErr.Succeeded) != 0 || len(*forms) != 1 {
+		t.Fatalf("%d failed chunks, %d succeeded items after %d requests; want 3, 0, 1", len(editErr.Failed), len(editErr.Succeeded), len(*forms))
+	}
+}
@@ -0,0 +1,31 @@
+package tags
+
+import (
+	"context"
+
+	"github.com/go-resty/resty/v2"
+	"github.com/hyperreal64/go-inoreader/api"
+	"github.com/hyperreal64/go-inoreader/stream"
+	"github.com/pkg/errors"
+)
+
+// Renames the tag or folder `label` to `newLabel`, both label streams such
+// as stream.Label("Go"). Sends a POST request.
+func RenameLabel(ctx context.Context, rc *resty.Client, label, newLabel stream.StreamID) error {
+
+	for _, id := range []stream.StreamID{label, newLabel} {
+		if id.Kind() != stream.KindLabel {
+			return errors.Errorf("Unable to rename tag: %q is not a label", id)
+		}
+	}
+
+	resp, err := rc.R().
+		SetContext(ctx).
+		SetQueryParams(map[string]string{
+			"s":    string(label),
+			"dest": string(newLabel),
+		}).
+		Post(api.Endpoint(rc, renameTagURL))
+
+	return api.CheckResponseContext(ctx, resp, err)
+}
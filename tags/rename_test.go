@@ -0,0 +1,33 @@
+package tags
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/go-resty/resty/v2"
+	"github.com/hyperreal64/go-inoreader/stream"
+)
+
+func TestRenameLabel(t *testing.T) {
+	var gotURI string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotURI = r.URL.Path + "?" + r.URL.RawQuery
+		w.Write([]byte("OK"))
+	}))
+	defer srv.Close()
+
+	rc := resty.New().SetHostURL(srv.URL)
+	if err := RenameLabel(context.Background(), rc, stream.Label("linux"), stream.Label("foss")); err != nil {
+		t.Fatal(err)
+	}
+
+	if want := "/rename-tag?dest=user%2F-%2Flabel%2Ffoss&s=user%2F-%2Flabel%2Flinux"; gotURI != want {
+		t.Fatalf("requested %s, want %s", gotURI, want)
+	}
+
+	if err := RenameLabel(context.Background(), rc, stream.Label("linux"), "foss"); err == nil {
+		t.Fatal("RenameLabel accepted a bare name")
+	}
+}
@@ -0,0 +1,171 @@
+package tags
+
+import (
+	"context"
+
+	"github.com/go-resty/resty/v2"
+	"github.com/hyperreal64/go-inoreader/api"
+	"github.com/hyperreal64/go-inoreader/stream"
+	"github.com/pkg/errors"
+)
+
+// TagKind is the kind of an entry in the tag list.
+type TagKind int
+
+const (
+	// KindUnknown is a label listed without its type, as happens when
+	// TagListOptions.Types is false.
+	KindUnknown TagKind = iota
+	KindFolder
+	KindTag
+	KindActiveSearch
+	KindState
+)
+
+func (k TagKind) String() string {
+
+	switch k {
+	case KindFolder:
+		return "folder"
+	case KindTag:
+		return "tag"
+	case KindActiveSearch:
+		return "active search"
+	case KindState:
+		return "state"
+	default:
+		return "unknown"
+	}
+}
+
+// Tag JSON response; one folder, tag, active search or state of the tag
+// list. The counts are only sent with TagListOptions.Counts.
+type Tag struct {
+	ID          stream.StreamID `json:"id"`
+	Sortid      string          `json:"sortid"`
+	UnreadCount api.FlexInt     `json:"unread_count"`
+	UnseenCount api.FlexInt     `json:"unseen_count"`
+	Type        string          `json:"type"`
+}
+
+// Kind classifies the entry from its type, or from its ID for states and
+// active searches.
+func (t *Tag) Kind() TagKind {
+
+	switch t.Type {
+	case "folder":
+		return KindFolder
+	case "tag":
+		return KindTag
+	case "active_search":
+		return KindActiveSearch
+	}
+
+	switch t.ID.Kind() {
+	case stream.KindState:
+		return KindState
+	case stream.KindActiveSearch:
+		return KindActiveSearch
+	}
+
+	return KindUnknown
+}
+
+// Name returns the name of the entry taken from its ID: the label or active
+// search name, or the state name, such as "starred", for states. Entries
+// with an ID of another form are named by the whole ID.
+func (t *Tag) Name() string {
+
+	if name, ok := t.ID.Label(); ok {
+		return name
+	}
+
+	if name, ok := t.ID.ActiveSearch(); ok {
+		return name
+	}
+
+	if state, ok := t.ID.State(); ok {
+		return string(state)
+	}
+
+	return string(t.ID)
+}
+
+// Returns the entries of l of kind `kind`.
+func (l *TagFolderList) ofKind(kind TagKind) []Tag {
+
+	var tags []Tag
+	for _, t := range l.Tags {
+		if t.Kind() == kind {
+			tags = append(tags, t)
+		}
+	}
+
+	return tags
+}
+
+// Folders returns the folders of the list.
+func (l *TagFolderList) Folders() []Tag {
+	return l.ofKind(KindFolder)
+}
+
+// TagsOnly returns the tags of the list, leaving out folders, active
+// searches and states.
+func (l *TagFolderList) TagsOnly() []Tag {
+	return l.ofKind(KindTag)
+}
+
+// ActiveSearches returns the active searches of the list.
+func (l *TagFolderList) ActiveSearches() []Tag {
+	return l.ofKind(KindActiveSearch)
+}
+
+// FolderSet returns the folders of the list as a stream.FolderSet, for
+// stream.Item.Folders and stream.Item.Tags.
+func (l *TagFolderList) FolderSet() stream.FolderSet {
+
+	folders := l.Folders()
+	ids := make([]stream.StreamID, len(folders))
+	for i, f := range folders {
+		ids[i] = f.ID
+	}
+
+	return stream.NewFolderSet(ids...)
+}
+
+// TagListOptions are the parameters of a tag/list request.
+type TagListOptions struct {
+	// Types asks for the type of every entry, which Tag.Kind needs to tell
+	// folders from tags.
+	Types bool
+
+	// Counts asks for the unread and unseen counts.
+	Counts bool
+}
+
+// Gets the list of folders, tags, active searches and states. A nil opts
+// asks for neither types nor counts.
+func GetTagListWithOptions(ctx context.Context, rc *resty.Client, opts *TagListOptions) (tfl *TagFolderList, err error) {
+
+	params := map[string]string{}
+	if opts != nil && opts.Types {
+		params["types"] = "1"
+	}
+	if opts != nil && opts.Counts {
+		params["counts"] = "1"
+	}
+
+	resp, err := rc.R().
+		SetContext(ctx).
+		SetQueryParams(params).
+		Get(api.Endpoint(rc, tagListURL))
+	if err := api.CheckResponseContext(ctx, resp, err); err != nil {
+		return nil, err
+	}
+
+	if err := resty.Unmarshalc(rc, "application/json", resp.Body(), &tfl); err != nil {
+		return nil, errors.Wrapf(err, "Could not unmarshal JSON object: %v", tfl)
+	}
+
+	return tfl, nil
+}
@@ -0,0 +1,69 @@
+package tags
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/go-resty/resty/v2"
+	"github.com/hyperreal64/go-inoreader/stream"
+)
+
+func TestGetTagListWithOptions(t *testing.T) {
+	var gotQuery string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotQuery = r.URL.RawQuery
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"tags": [
+			{"id": "user/1005869311/state/com.google/starred", "sortid": "FFFFFFFF"},
+			{"id": "user/1005869311/label/Tech", "sortid": "00000001", "unread_count": 9, "unseen_count": "2", "type": "folder"},
+			{"id": "user/1005869311/label/Later", "sortid": "00000002", "unread_count": 1, "type": "tag"},
+			{"id": "user/1005869311/label/Go releases", "sortid": "00000003", "type": "active_search"},
+			{"id": "user/1005869311/label/News", "sortid": "00000004", "type": "folder"},
+			{"id": "user/1005869311/active_search/go/releases", "sortid": "00000005"}
+		]}`))
+	}))
+	defer srv.Close()
+
+	rc := resty.New().SetHostURL(srv.URL)
+	tfl, err := GetTagListContext(context.Background(), rc)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if gotQuery != "counts=1&types=1" {
+		t.Fatalf("query %q, want counts=1&types=1", gotQuery)
+	}
+
+	var kinds []string
+	for _, tag := range tfl.Tags {
+		kinds = append(kinds, tag.Kind().String()+":"+tag.Name())
+	}
+	if want := "[state:starred folder:Tech tag:Later active search:Go releases folder:News active search:go/releases]"; fmt.Sprint(kinds) != want {
+		t.Fatalf("kinds %v, want %s", kinds, want)
+	}
+
+	folders := tfl.Folders()
+	if len(folders) != 2 || folders[0].UnreadCount != 9 || folders[0].UnseenCount != 2 {
+		t.Fatalf("Folders() = %#v", folders)
+	}
+
+	if only := tfl.TagsOnly(); len(only) != 1 || only[0].Name() != "Later" || len(tfl.ActiveSearches()) != 2 {
+		t.Fatalf("TagsOnly() = %#v", only)
+	}
+
+	item := stream.Item{Categories: []stream.StreamID{"user/1005869311/label/Tech", "user/1005869311/label/Later"}}
+	if fs := tfl.FolderSet(); fmt.Sprint(item.Folders(fs)) != "[Tech]" || fmt.Sprint(item.Tags(fs)) != "[Later]" {
+		t.Fatalf("FolderSet() = %v", fs)
+	}
+
+	if _, err := GetTagListWithOptions(context.Background(), rc, nil); err != nil {
+		t.Fatal(err)
+	}
+
+	if gotQuery != "" {
+		t.Fatalf("query %q for nil options, want none", gotQuery)
+	}
+}
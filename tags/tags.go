@@ -1,77 +1,70 @@
 package tags
 
 import (
+	"context"
+
 	"github.com/go-resty/resty/v2"
-	"github.com/pkg/errors"
+	"github.com/hyperreal64/go-inoreader/api"
 )
 
-// API URLs for resty.Client requests
+// API endpoint paths, relative to api.DefaultBaseURL
 const (
-	tagListURL   = "https://www.inoreader.com/reader/api/0/tag/list?types=1&counts=1"
-	renameTagURL = "https://www.inoreader.com/reader/api/0/rename-tag"
-	editTagURL   = "https://www.inoreader.com/reader/api/0/edit-tag"
+	tagListURL    = "tag/list"
+	renameTagURL  = "rename-tag"
+	editTagURL    = "edit-tag"
+	disableTagURL = "disable-tag"
 )
 
 // TagFolderList JSON response
 type TagFolderList struct {
-	Tags []struct {
-		ID          string `json:"id"`
-		Sortid      string `json:"sortid"`
-		UnreadCount int64  `json:"unread_count"`
-		Type        string `json:"type"`
-	} `json:"tags"`
+	Tags []Tag `json:"tags"`
 }
 
 // Get list of tags. Sends a GET request and returns JSON response as
 // TagFolderList struct.
 func GetTagList(rc *resty.Client) (tfl *TagFolderList, err error) {
+	return GetTagListContext(context.Background(), rc)
+}
 
-	resp, err := rc.R().Get(tagListURL)
-	if err != nil {
-		return nil, err
-	}
-
-	if err := resty.Unmarshalc(rc, "application/json", resp.Body(), &tfl); err != nil {
-		return nil, errors.Wrapf(err, "Could not unmarshal JSON object: %v", tfl)
-	}
-
-	return tfl, nil
+// Same as GetTagList, but the request is bound to ctx.
+func GetTagListContext(ctx context.Context, rc *resty.Client) (tfl *TagFolderList, err error) {
+	return GetTagListWithOptions(ctx, rc, &TagListOptions{Types: true, Counts: true})
 }
 
 // Rename tag specified in query parameters. Sends a POST request.
+// RenameLabel takes the stream IDs instead.
 func RenameTag(rc *resty.Client, params map[string]string) error {
+	return RenameTagContext(context.Background(), rc, params)
+}
 
-	_, err := rc.R().
+// Same as RenameTag, but the request is bound to ctx.
+func RenameTagContext(ctx context.Context, rc *resty.Client, params map[string]string) error {
+
+	resp, err := rc.R().
+		SetContext(ctx).
 		SetQueryParams(params).
-		Post(renameTagURL)
-	if err != nil {
+		Post(api.Endpoint(rc, renameTagURL))
+	if err := api.CheckResponseContext(ctx, resp, err); err != nil {
 		return err
 	}
 
 	return nil
 }
 
-// func DeleteTag(rc *resty.Client, tagName string) error {
-
-// 	_, err := rc.R().
-// 		SetQueryParams(map[string]string{
-// 			"s": tagName,
-// 		}).
-// 		Post(baseURL + "/disable-tag")
-// 	if err != nil {
-// 		return err
-// 	}
-
-// 	return
-// }
-
 // Edit tag specified in query parameters. Sends a POST request.
+// EditItemTags takes the stream IDs instead.
 func EditTag(rc *resty.Client, params map[string]string) error {
+	return EditTagContext(context.Background(), rc, params)
+}
+
+// Same as EditTag, but the request is bound to ctx.
+func EditTagContext(ctx context.Context, rc *resty.Client, params map[string]string) error {
 
-	_, err := rc.R().
+	resp, err := rc.R().
+		SetContext(ctx).
 		SetQueryParams(params).
-		Post(editTagURL)
-	if err != nil {
+		Post(api.Endpoint(rc, editTagURL))
+	if err := api.CheckResponseContext(ctx, resp, err); err != nil {
 		return err
 	}
 
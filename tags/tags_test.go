@@ -5,6 +5,7 @@ import (
 	"testing"
 
 	"github.com/hyperreal64/go-inoreader/config"
+	"github.com/hyperreal64/go-inoreader/stream"
 )
 
 func TestGetTagList(t *testing.T) {
@@ -26,7 +27,7 @@ func TestEditTag(t *testing.T) {
 	defer cancel()
 
 	params := map[string]string{
-		"a": "user/-/state/com.google/starred",
+		"a": stream.State(stream.Starred).String(),
 		"i": "33050093431",
 	}
 
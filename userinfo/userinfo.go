@@ -1,11 +1,14 @@
 package userinfo
 
 import (
+	"context"
+
 	"github.com/go-resty/resty/v2"
+	"github.com/hyperreal64/go-inoreader/api"
 	"github.com/pkg/errors"
 )
 
-const userInfoURL = "https://www.inoreader.com/reader/api/0/user-info"
+const userInfoURL = "user-info"
 
 // UserInfo JSON response
 // Output looks like:
@@ -19,20 +22,25 @@ const userInfoURL = "https://www.inoreader.com/reader/api/0/user-info"
 //     "isMultiLoginEnabled": false
 // }
 type UserInfo struct {
-	UserID              string `json:"userId"`
-	UserName            string `json:"userName"`
-	UserProfileID       string `json:"userProfileId"`
-	UserEmail           string `json:"userEmail"`
-	IsBloggerUser       bool   `json:"isBloggerUser"`
-	SignupTimeSec       int64  `json:"signupTimeSec"`
-	IsMultiLoginEnabled bool   `json:"isMultiLoginEnabled"`
+	UserID              string       `json:"userId"`
+	UserName            string       `json:"userName"`
+	UserProfileID       string       `json:"userProfileId"`
+	UserEmail           string       `json:"userEmail"`
+	IsBloggerUser       bool         `json:"isBloggerUser"`
+	SignupTimeSec       api.UnixTime `json:"signupTimeSec"`
+	IsMultiLoginEnabled bool         `json:"isMultiLoginEnabled"`
 }
 
 // Gets the user info. Sends a GET request and returns JSON response
 // as a UserInfo struct.
 func GetUserInfo(rc *resty.Client) (userinfo *UserInfo, err error) {
-	resp, err := rc.R().Get(userInfoURL)
-	if err != nil {
+	return GetUserInfoContext(context.Background(), rc)
+}
+
+// Same as GetUserInfo, but the request is bound to ctx.
+func GetUserInfoContext(ctx context.Context, rc *resty.Client) (userinfo *UserInfo, err error) {
+	resp, err := rc.R().SetContext(ctx).Get(api.Endpoint(rc, userInfoURL))
+	if err := api.CheckResponseContext(ctx, resp, err); err != nil {
 		return nil, errors.Wrap(err, "Could not get user info")
 	}
 